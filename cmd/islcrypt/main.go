@@ -0,0 +1,413 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"iSL1/customlib"
+	"iSL1/deal"
+	"iSL1/des"
+	"iSL1/rijndael"
+	"iSL1/tdes"
+)
+
+var modes = map[string]customlib.CipherMode{
+	"ecb":         customlib.ModeECB,
+	"cbc":         customlib.ModeCBC,
+	"pcbc":        customlib.ModePCBC,
+	"cfb":         customlib.ModeCFB,
+	"ofb":         customlib.ModeOFB,
+	"ctr":         customlib.ModeCTR,
+	"randomdelta": customlib.ModeRandomDelta,
+	"gcm":         customlib.ModeGCM,
+	"cbc-cs1":     customlib.ModeCBCCS1,
+	"cbc-cs2":     customlib.ModeCBCCS2,
+	"cbc-cs3":     customlib.ModeCBCCS3,
+}
+
+var kdfs = map[string]customlib.KDFID{
+	"pbkdf2-sha256": customlib.KDFPBKDF2SHA256,
+	"pbkdf2-sha512": customlib.KDFPBKDF2SHA512,
+	"scrypt":        customlib.KDFScrypt,
+}
+
+var macs = map[string]customlib.MACID{
+	"none":        customlib.MACNone,
+	"hmac-sha256": customlib.MACHMACSHA256,
+}
+
+var paddings = map[string]customlib.PaddingMode{
+	"zeros":    customlib.PaddingZeros,
+	"ansix923": customlib.PaddingANSIX923,
+	"pkcs7":    customlib.PaddingPKCS7,
+	"iso10126": customlib.PaddingISO10126,
+	"iso7816":  customlib.PaddingISO7816,
+}
+
+const maxHeaderSize = 1 << 17
+
+type cipherOptions struct {
+	algorithm string
+	blockSize int
+	keySize   int
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "encrypt":
+		err = runCrypt(os.Args[2:], false)
+	case "decrypt":
+		err = runCrypt(os.Args[2:], true)
+	case "keygen":
+		err = runKeygen(os.Args[2:])
+	case "bench":
+		err = runBench(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Использование: islcrypt <encrypt|decrypt|keygen|bench> [флаги]")
+}
+
+func addCipherFlags(fs *flag.FlagSet, opts *cipherOptions) {
+	fs.StringVar(&opts.algorithm, "alg", "des", "алгоритм: des, 3des, deal, rijndael")
+	fs.IntVar(&opts.blockSize, "block", 16, "размер блока Rijndael в байтах: 16, 24, 32")
+	fs.IntVar(&opts.keySize, "keysize", 0, "размер ключа в байтах (по умолчанию зависит от алгоритма)")
+}
+
+func runCrypt(args []string, decrypt bool) error {
+	fs := flag.NewFlagSet("crypt", flag.ContinueOnError)
+	var opts cipherOptions
+	addCipherFlags(fs, &opts)
+	modeName := fs.String("mode", "cbc", "режим шифрования: ecb, cbc, pcbc, cfb, ofb, ctr, randomdelta, gcm, cbc-cs1, cbc-cs2, cbc-cs3")
+	paddingName := fs.String("padding", "pkcs7", "режим набивки: zeros, ansix923, pkcs7, iso10126, iso7816")
+	ivHex := fs.String("iv", "", "вектор инициализации в hex (по умолчанию случайный)")
+	keyHex := fs.String("key", "", "ключ в hex")
+	keyFile := fs.String("keyfile", "", "файл с ключом в hex")
+	passphrase := fs.String("pass", "", "пароль для получения ключа")
+	kdfName := fs.String("kdf", "pbkdf2-sha256", "функция получения ключа из пароля: pbkdf2-sha256, pbkdf2-sha512, scrypt")
+	macName := fs.String("mac", "none", "код аутентификации файла: none, hmac-sha256")
+	input := fs.String("in", "-", "входной файл или - для stdin")
+	output := fs.String("out", "-", "выходной файл или - для stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	mode, ok := modes[strings.ToLower(*modeName)]
+	if !ok {
+		return fmt.Errorf("неизвестный режим шифрования %q", *modeName)
+	}
+	padding, ok := paddings[strings.ToLower(*paddingName)]
+	if !ok {
+		return fmt.Errorf("неизвестный режим набивки %q", *paddingName)
+	}
+	mac, ok := macs[strings.ToLower(*macName)]
+	if !ok {
+		return fmt.Errorf("неизвестный код аутентификации %q", *macName)
+	}
+
+	cipher, err := newCipher(opts)
+	if err != nil {
+		return err
+	}
+
+	var iv []byte
+	if *ivHex != "" {
+		if *passphrase != "" {
+			return errors.New("-iv нельзя использовать вместе с -pass")
+		}
+		iv, err = hex.DecodeString(*ivHex)
+		if err != nil {
+			return fmt.Errorf("неверный IV: %w", err)
+		}
+	}
+
+	in, closeIn, err := openInput(*input)
+	if err != nil {
+		return err
+	}
+	defer closeIn()
+
+	var ctx *customlib.CryptoContext
+	switch {
+	case *passphrase != "" && decrypt:
+		ctx, err = passwordDecryptContext(opts, cipher, *passphrase, in)
+	case *passphrase != "":
+		kdf, ok := kdfs[strings.ToLower(*kdfName)]
+		if !ok {
+			return fmt.Errorf("неизвестная функция получения ключа %q", *kdfName)
+		}
+
+		var size int
+		size, err = keySize(opts)
+		if err != nil {
+			return err
+		}
+
+		ctx, err = customlib.NewCryptoContextFromPassword([]byte(*passphrase), cipher, mode, padding, customlib.PasswordOptions{KDF: kdf, KeySize: size})
+	default:
+		var key []byte
+		key, err = loadKey(*keyHex, *keyFile)
+		if err != nil {
+			return err
+		}
+
+		if iv == nil && !decrypt && mode != customlib.ModeECB && mode != customlib.ModeRandomDelta {
+			ivSize := cipher.BlockSize()
+			if mode == customlib.ModeGCM {
+				ivSize = 12
+			}
+			iv = make([]byte, ivSize)
+			if _, err := rand.Read(iv); err != nil {
+				return err
+			}
+		}
+
+		ctx, err = customlib.NewCryptoContextWithCipher(key, mode, padding, iv, cipher)
+	}
+	if err != nil {
+		return err
+	}
+	if !decrypt || mac != customlib.MACNone {
+		if err := ctx.SetFileMAC(mac); err != nil {
+			return err
+		}
+	}
+
+	runCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *input != "-" && *output != "-" {
+		if decrypt {
+			return ctx.DecryptFileContext(runCtx, *input, *output, nil)
+		}
+		return ctx.EncryptFileContext(runCtx, *input, *output, nil)
+	}
+
+	out, closeOut, err := openOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	if decrypt {
+		err = ctx.DecryptStream(runCtx, in, out)
+	} else {
+		err = ctx.EncryptStream(runCtx, in, out)
+	}
+
+	closeErr := closeOut()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func runKeygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	var opts cipherOptions
+	addCipherFlags(fs, &opts)
+	output := fs.String("out", "-", "файл для ключа или - для stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	size, err := keySize(opts)
+	if err != nil {
+		return err
+	}
+
+	key := make([]byte, size)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+
+	out, closeOut, err := openOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, hex.EncodeToString(key))
+	closeErr := closeOut()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	var opts cipherOptions
+	addCipherFlags(fs, &opts)
+	modeName := fs.String("mode", "", "режим шифрования (по умолчанию все режимы)")
+	size := fs.Int("size", 1<<20, "объём данных в байтах")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	names := []string{"ecb", "cbc", "pcbc", "cfb", "ofb", "ctr", "randomdelta", "gcm"}
+	if *modeName != "" {
+		names = []string{strings.ToLower(*modeName)}
+	}
+
+	keyLength, err := keySize(opts)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, *size)
+
+	for _, name := range names {
+		mode, ok := modes[name]
+		if !ok {
+			return fmt.Errorf("неизвестный режим шифрования %q", name)
+		}
+
+		cipher, err := newCipher(opts)
+		if err != nil {
+			return err
+		}
+		if mode == customlib.ModeGCM && cipher.BlockSize() != 16 {
+			continue
+		}
+
+		iv := make([]byte, cipher.BlockSize())
+		ctx, err := customlib.NewCryptoContextWithCipher(make([]byte, keyLength), mode, customlib.PaddingPKCS7, iv, cipher)
+		if err != nil {
+			return err
+		}
+
+		start := time.Now()
+		if _, err := ctx.Encrypt(data); err != nil {
+			return err
+		}
+		elapsed := time.Since(start)
+
+		fmt.Printf("%-6s %-12s %10.2f МБ/с\n", opts.algorithm, name, float64(*size)/(1<<20)/elapsed.Seconds())
+	}
+
+	return nil
+}
+
+func newCipher(opts cipherOptions) (customlib.BlockCipher, error) {
+	switch strings.ToLower(opts.algorithm) {
+	case "des":
+		return des.NewDES()
+	case "3des", "tdes":
+		return tdes.NewTDES()
+	case "deal":
+		return deal.NewDEAL()
+	case "rijndael", "aes":
+		return rijndael.NewRijndael(opts.blockSize, rijndael.DefaultModulus)
+	default:
+		return nil, fmt.Errorf("неизвестный алгоритм %q", opts.algorithm)
+	}
+}
+
+func keySize(opts cipherOptions) (int, error) {
+	if opts.keySize != 0 {
+		return opts.keySize, nil
+	}
+
+	switch strings.ToLower(opts.algorithm) {
+	case "des":
+		return 8, nil
+	case "3des", "tdes":
+		return 24, nil
+	case "deal", "rijndael", "aes":
+		return 16, nil
+	default:
+		return 0, fmt.Errorf("неизвестный алгоритм %q", opts.algorithm)
+	}
+}
+
+func loadKey(keyHex string, keyFile string) ([]byte, error) {
+	switch {
+	case keyHex != "":
+		return hex.DecodeString(keyHex)
+	case keyFile != "":
+		content, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		return hex.DecodeString(strings.TrimSpace(string(content)))
+	default:
+		return nil, errors.New("необходимо указать -key, -keyfile или -pass")
+	}
+}
+
+func passwordDecryptContext(opts cipherOptions, cipher customlib.BlockCipher, passphrase string, in *bufio.Reader) (*customlib.CryptoContext, error) {
+	headerBytes, err := in.Peek(maxHeaderSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+
+	header, err := customlib.ReadFileHeader(bytes.NewReader(headerBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := keySize(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return customlib.NewCryptoContextFromPasswordHeader([]byte(passphrase), cipher, header, size)
+}
+
+func openInput(path string) (*bufio.Reader, func() error, error) {
+	if path == "-" {
+		return bufio.NewReaderSize(os.Stdin, maxHeaderSize), func() error { return nil }, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bufio.NewReaderSize(file, maxHeaderSize), file.Close, nil
+}
+
+func openOutput(path string) (io.Writer, func() error, error) {
+	if path == "-" {
+		writer := bufio.NewWriter(os.Stdout)
+		return writer, writer.Flush, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	writer := bufio.NewWriter(file)
+	return writer, func() error {
+		err := writer.Flush()
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,31 @@
+package customlib
+
+func GetBit(value []byte, bitIndex int, indexFromLSB bool) int {
+	byteIndex := bitIndex / 8
+	bitOffset := bitIndex % 8
+
+	if indexFromLSB {
+		return int((value[byteIndex] >> bitOffset) & 1)
+	} else {
+		return int((value[byteIndex] >> (7 - bitOffset)) & 1)
+	}
+}
+
+func SetBit(value []byte, bitIndex int, bit int, indexFromLSB bool) {
+	byteIndex := bitIndex / 8
+	bitOffset := bitIndex % 8
+
+	if indexFromLSB {
+		if bit == 1 {
+			value[byteIndex] |= (1 << bitOffset)
+		} else {
+			value[byteIndex] &^= (1 << bitOffset)
+		}
+	} else {
+		if bit == 1 {
+			value[byteIndex] |= (1 << (7 - bitOffset))
+		} else {
+			value[byteIndex] &^= (1 << (7 - bitOffset))
+		}
+	}
+}
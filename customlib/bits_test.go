@@ -0,0 +1,30 @@
+package customlib_test
+
+import (
+	"testing"
+
+	"iSL1/customlib"
+)
+
+func TestGetSetBit(t *testing.T) {
+	value := []byte{0b10000001, 0b01000000}
+
+	if customlib.GetBit(value, 0, false) != 1 || customlib.GetBit(value, 1, false) != 0 || customlib.GetBit(value, 9, false) != 1 {
+		t.Error("неверное чтение битов от старшего")
+	}
+	if customlib.GetBit(value, 0, true) != 1 || customlib.GetBit(value, 7, true) != 1 || customlib.GetBit(value, 14, true) != 1 {
+		t.Error("неверное чтение битов от младшего")
+	}
+
+	out := make([]byte, 2)
+	customlib.SetBit(out, 0, 1, false)
+	customlib.SetBit(out, 15, 1, true)
+	if out[0] != 0b10000000 || out[1] != 0b10000000 {
+		t.Errorf("неверная запись битов: %08b", out)
+	}
+
+	customlib.SetBit(out, 0, 0, false)
+	if out[0] != 0 {
+		t.Errorf("бит не сброшен: %08b", out[0])
+	}
+}
@@ -0,0 +1,277 @@
+package customlib_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/des"
+	"iSL1/rijndael"
+)
+
+var (
+	keyA = bytes.Repeat([]byte{0x11}, 16)
+	keyB = bytes.Repeat([]byte{0x22}, 16)
+)
+
+func newAESContext(t *testing.T, key []byte, mode customlib.CipherMode, iv []byte) *customlib.CryptoContext {
+	t.Helper()
+	cipher, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := customlib.NewCryptoContextWithCipher(key, mode, customlib.PaddingPKCS7, iv, cipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func TestEncryptWriterKeepsKeyAfterSetKey(t *testing.T) {
+	iv := make([]byte, 16)
+	plaintext := testMessage(1000)
+	ctx := newAESContext(t, keyA, customlib.ModeCBC, iv)
+
+	var out bytes.Buffer
+	writer, err := customlib.NewEncryptWriter(&out, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write(plaintext[:500]); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.SetKey(keyB); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write(plaintext[500:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	decrypted, err := newAESContext(t, keyA, customlib.ModeCBC, iv).Decrypt(out.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Error("поток сменил ключ посреди шифрования")
+	}
+}
+
+func TestDecryptReaderKeepsKeyAfterSetKey(t *testing.T) {
+	iv := make([]byte, 16)
+	plaintext := testMessage(1000)
+	ciphertext, err := newAESContext(t, keyA, customlib.ModeCBC, iv).Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := newAESContext(t, keyA, customlib.ModeCBC, iv)
+	reader, err := customlib.NewDecryptReader(bytes.NewReader(ciphertext), ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	head := make([]byte, 100)
+	if _, err := io.ReadFull(reader, head); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.SetKey(keyB); err != nil {
+		t.Fatal(err)
+	}
+	tail, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(append(head, tail...), plaintext) {
+		t.Error("поток сменил ключ посреди расшифрования")
+	}
+}
+
+func TestSeekableReaderKeepsKeyAfterSetKey(t *testing.T) {
+	iv := make([]byte, 16)
+	plaintext := testMessage(1000)
+	ciphertext, err := newAESContext(t, keyA, customlib.ModeCTR, iv).Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := newAESContext(t, keyA, customlib.ModeCTR, iv)
+	sr, err := customlib.NewSeekableReader(bytes.NewReader(ciphertext), int64(len(ciphertext)), ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.SetKey(keyB); err != nil {
+		t.Fatal(err)
+	}
+
+	part := make([]byte, 200)
+	if _, err := sr.ReadAt(part, 300); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(part, plaintext[300:500]) {
+		t.Error("SeekableReader сменил ключ после SetKey")
+	}
+}
+
+func TestSetKeyDoesNotTouchCallerBuffers(t *testing.T) {
+	iv := bytes.Repeat([]byte{0x33}, 16)
+	key := append([]byte(nil), keyA...)
+	ctx := newAESContext(t, key, customlib.ModeCBC, iv)
+
+	expected, err := newAESContext(t, keyA, customlib.ModeCBC, bytes.Repeat([]byte{0x33}, 16)).Encrypt(testMessage(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iv[0] ^= 0xFF
+	key[0] ^= 0xFF
+
+	ciphertext, err := ctx.Encrypt(testMessage(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ciphertext, expected) {
+		t.Error("изменение буферов вызывающего повлияло на контекст")
+	}
+}
+
+func TestSetKeyWithoutFactory(t *testing.T) {
+	aes, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := customlib.NewCryptoContextWithCipher(keyA, customlib.ModeECB, customlib.PaddingPKCS7, nil, struct{ customlib.BlockCipher }{aes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.SetKey(keyB); err == nil {
+		t.Error("ожидалась ошибка смены ключа для шифра без BlockCipherFactory")
+	}
+}
+
+func TestConcurrentSetKeyAndCrypt(t *testing.T) {
+	iv := make([]byte, 8)
+	desCipher, err := des.NewDES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := customlib.NewCryptoContextWithCipher(keyA[:8], customlib.ModeCBC, customlib.PaddingPKCS7, iv, desCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := testMessage(300)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			keys := [][]byte{keyA[:8], keyB[:8]}
+			for j := 0; j < 20; j++ {
+				if err := ctx.SetKey(keys[(i+j)%2]); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(i)
+	}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				ciphertext, err := ctx.Encrypt(plaintext)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(ciphertext) != 304 {
+					errs <- io.ErrShortWrite
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				var sealed bytes.Buffer
+				if err := ctx.EncryptStream(context.Background(), bytes.NewReader(plaintext), &sealed); err != nil {
+					errs <- err
+					return
+				}
+
+				writer, err := customlib.NewEncryptWriter(io.Discard, ctx)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if _, err := writer.Write(plaintext); err != nil {
+					errs <- err
+					return
+				}
+				if err := writer.Close(); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestContextsDoNotShareCipherInstance(t *testing.T) {
+	shared, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := make([]byte, 16)
+	plaintext := testMessage(64)
+
+	ctxA, err := customlib.NewCryptoContextWithCipher(keyA, customlib.ModeCBC, customlib.PaddingPKCS7, iv, shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := ctxA.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := customlib.NewCryptoContextWithCipher(keyB, customlib.ModeCBC, customlib.PaddingPKCS7, iv, shared); err != nil {
+		t.Fatal(err)
+	}
+	after, err := ctxA.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("создание второго контекста изменило ключ первого")
+	}
+}
+
+func TestXTSRejectsSharedCipher(t *testing.T) {
+	shared, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := customlib.NewXTSCryptoContext(testMessage(32), shared, shared, 512); err == nil {
+		t.Error("ожидалась ошибка для одного экземпляра шифра данных и твиков")
+	}
+}
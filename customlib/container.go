@@ -0,0 +1,258 @@
+package customlib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const FileFormatVersion uint8 = 2
+
+const (
+	ctrFlagLittleEndian byte = 1 << iota
+	ctrFlagErrorOnOverflow
+)
+
+var fileMagic = []byte("ISLC")
+
+var (
+	ErrInvalidHeader      = errors.New("неверный заголовок зашифрованного файла")
+	ErrUnsupportedVersion = errors.New("неподдерживаемая версия формата файла")
+)
+
+type AlgorithmID uint16
+
+const (
+	AlgorithmUnknown AlgorithmID = iota
+	AlgorithmDES
+	AlgorithmTDES
+	AlgorithmDEAL
+	AlgorithmRijndael
+)
+
+type AlgorithmIdentifier interface {
+	AlgorithmID() AlgorithmID
+}
+
+type KDFID uint8
+
+const (
+	KDFNone KDFID = iota
+	KDFPBKDF2SHA256
+	KDFPBKDF2SHA512
+	KDFScrypt
+)
+
+type KDFParams struct {
+	ID     KDFID
+	Params []byte
+}
+
+type MACID uint8
+
+const (
+	MACNone MACID = iota
+	MACHMACSHA256
+)
+
+type FileHeader struct {
+	Version     uint8
+	Algorithm   AlgorithmID
+	BlockSize   int
+	Mode        CipherMode
+	Padding     PaddingMode
+	IV          []byte
+	KDF         KDFParams
+	MAC         MACID
+	CTRLayout   CTRLayout
+	SegmentBits int
+	SectorSize  int
+}
+
+func (ctx *CryptoContext) fileHeader() *FileHeader {
+	algorithm := AlgorithmUnknown
+	if identifier, ok := ctx.cipher.(AlgorithmIdentifier); ok {
+		algorithm = identifier.AlgorithmID()
+	}
+
+	return &FileHeader{
+		Version:     FileFormatVersion,
+		Algorithm:   algorithm,
+		BlockSize:   ctx.blockSize,
+		Mode:        ctx.mode,
+		Padding:     ctx.padding,
+		IV:          ctx.iv,
+		KDF:         ctx.kdf,
+		MAC:         ctx.fileMAC,
+		CTRLayout:   ctx.ctrLayout,
+		SegmentBits: ctx.segmentBits,
+		SectorSize:  ctx.sectorSize,
+	}
+}
+
+func (h *FileHeader) MarshalBinary() ([]byte, error) {
+	if h.BlockSize <= 0 || h.BlockSize > 255 {
+		return nil, errors.New("неподдерживаемый размер блока")
+	}
+	if h.Mode < 0 || h.Mode > 255 || h.Padding < 0 || h.Padding > 255 {
+		return nil, errors.New("режим не помещается в заголовок файла")
+	}
+	if len(h.IV) > 255 {
+		return nil, errors.New("вектор инициализации слишком длинный")
+	}
+	if len(h.KDF.Params) > 0xFFFF {
+		return nil, errors.New("параметры KDF слишком длинные")
+	}
+	if h.CTRLayout.Offset < 0 || h.CTRLayout.Offset > 255 || h.CTRLayout.Width < 0 || h.CTRLayout.Width > 255 {
+		return nil, errors.New("раскладка счётчика не помещается в заголовок файла")
+	}
+	if h.SegmentBits < 0 || h.SegmentBits > 0xFFFF || h.SectorSize < 0 || int64(h.SectorSize) > 0xFFFFFFFF {
+		return nil, errors.New("размер сегмента или сектора не помещается в заголовок файла")
+	}
+
+	var buf bytes.Buffer
+	buf.Write(fileMagic)
+	buf.WriteByte(h.Version)
+	binary.Write(&buf, binary.BigEndian, uint16(h.Algorithm))
+	buf.WriteByte(byte(h.BlockSize))
+	buf.WriteByte(byte(h.Mode))
+	buf.WriteByte(byte(h.Padding))
+	buf.WriteByte(byte(len(h.IV)))
+	buf.Write(h.IV)
+	buf.WriteByte(byte(h.KDF.ID))
+	binary.Write(&buf, binary.BigEndian, uint16(len(h.KDF.Params)))
+	buf.Write(h.KDF.Params)
+	buf.WriteByte(byte(h.MAC))
+
+	if h.Version >= 2 {
+		var flags byte
+		if h.CTRLayout.LittleEndian {
+			flags |= ctrFlagLittleEndian
+		}
+		if h.CTRLayout.ErrorOnOverflow {
+			flags |= ctrFlagErrorOnOverflow
+		}
+
+		buf.WriteByte(byte(h.CTRLayout.Offset))
+		buf.WriteByte(byte(h.CTRLayout.Width))
+		buf.WriteByte(flags)
+		binary.Write(&buf, binary.BigEndian, uint16(h.SegmentBits))
+		binary.Write(&buf, binary.BigEndian, uint32(h.SectorSize))
+	}
+
+	return buf.Bytes(), nil
+}
+
+func ReadFileHeader(r io.Reader) (*FileHeader, error) {
+	fixed := make([]byte, len(fileMagic)+1)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	if !bytes.Equal(fixed[:len(fileMagic)], fileMagic) {
+		return nil, ErrInvalidHeader
+	}
+
+	h := &FileHeader{Version: fixed[len(fileMagic)]}
+	if h.Version < 1 || h.Version > FileFormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, h.Version)
+	}
+
+	body := make([]byte, 6)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	h.Algorithm = AlgorithmID(binary.BigEndian.Uint16(body[0:2]))
+	h.BlockSize = int(body[2])
+	h.Mode = CipherMode(body[3])
+	h.Padding = PaddingMode(body[4])
+
+	h.IV = make([]byte, body[5])
+	if _, err := io.ReadFull(r, h.IV); err != nil {
+		return nil, ErrInvalidHeader
+	}
+
+	kdf := make([]byte, 3)
+	if _, err := io.ReadFull(r, kdf); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	h.KDF.ID = KDFID(kdf[0])
+	h.KDF.Params = make([]byte, binary.BigEndian.Uint16(kdf[1:3]))
+	if _, err := io.ReadFull(r, h.KDF.Params); err != nil {
+		return nil, ErrInvalidHeader
+	}
+
+	mac := make([]byte, 1)
+	if _, err := io.ReadFull(r, mac); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	h.MAC = MACID(mac[0])
+	if h.MAC != MACNone && h.MAC != MACHMACSHA256 {
+		return nil, errors.New("неподдерживаемый алгоритм MAC в заголовке файла")
+	}
+	if h.BlockSize == 0 {
+		return nil, ErrInvalidHeader
+	}
+
+	if h.Version >= 2 {
+		layout := make([]byte, 9)
+		if _, err := io.ReadFull(r, layout); err != nil {
+			return nil, ErrInvalidHeader
+		}
+		if layout[2]&^(ctrFlagLittleEndian|ctrFlagErrorOnOverflow) != 0 {
+			return nil, ErrInvalidHeader
+		}
+		h.CTRLayout = CTRLayout{
+			Offset:          int(layout[0]),
+			Width:           int(layout[1]),
+			LittleEndian:    layout[2]&ctrFlagLittleEndian != 0,
+			ErrorOnOverflow: layout[2]&ctrFlagErrorOnOverflow != 0,
+		}
+		h.SegmentBits = int(binary.BigEndian.Uint16(layout[3:5]))
+		h.SectorSize = int(binary.BigEndian.Uint32(layout[5:9]))
+	}
+
+	return h, nil
+}
+
+func (ctx *CryptoContext) forFileHeader(h *FileHeader) (*CryptoContext, error) {
+	expected := ctx.fileHeader()
+	if h.Algorithm != expected.Algorithm {
+		return nil, errors.New("файл зашифрован другим алгоритмом")
+	}
+	if h.BlockSize != ctx.blockSize {
+		return nil, errors.New("размер блока в заголовке не совпадает с размером блока шифра")
+	}
+	if ctx.fileMAC != MACNone && h.MAC != ctx.fileMAC {
+		return nil, ErrAuthentication
+	}
+	if err := h.CTRLayout.validate(ctx.blockSize); err != nil {
+		return nil, err
+	}
+	blockBits := ctx.blockSize * 8
+	if h.SegmentBits < 0 || h.SegmentBits > blockBits || (h.SegmentBits != 0 && blockBits%h.SegmentBits != 0) {
+		return nil, errors.New("размер сегмента в заголовке не делит размер блока")
+	}
+	if h.Mode == ModeXTS && h.SectorSize < xtsBlockSize {
+		return nil, errors.New("размер сектора в заголовке меньше размера блока")
+	}
+
+	fileCtx := *ctx
+	fileCtx.mode = h.Mode
+	fileCtx.padding = h.Padding
+	fileCtx.iv = h.IV
+	fileCtx.kdf = h.KDF
+	fileCtx.fileMAC = h.MAC
+	fileCtx.ctrLayout = h.CTRLayout
+	fileCtx.segmentBits = h.SegmentBits
+	if h.Version >= 2 {
+		fileCtx.sectorSize = h.SectorSize
+	}
+	fileCtx.autoIV = false
+	if len(fileCtx.iv) == 0 {
+		fileCtx.iv = nil
+	}
+
+	return &fileCtx, nil
+}
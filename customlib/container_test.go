@@ -0,0 +1,116 @@
+package customlib_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/rijndael"
+)
+
+func TestFileHeaderRoundTrip(t *testing.T) {
+	headers := []*customlib.FileHeader{
+		{
+			Version:     customlib.FileFormatVersion,
+			Algorithm:   customlib.AlgorithmRijndael,
+			BlockSize:   16,
+			Mode:        customlib.ModeCTR,
+			Padding:     customlib.PaddingPKCS7,
+			IV:          testMessage(16),
+			KDF:         customlib.KDFParams{ID: customlib.KDFScrypt, Params: testMessage(18)},
+			MAC:         customlib.MACHMACSHA256,
+			CTRLayout:   customlib.CTRLayout{Offset: 12, Width: 4, LittleEndian: true, ErrorOnOverflow: true},
+			SegmentBits: 8,
+			SectorSize:  4096,
+		},
+		{
+			Version:   1,
+			Algorithm: customlib.AlgorithmDES,
+			BlockSize: 8,
+			Mode:      customlib.ModeCBC,
+			Padding:   customlib.PaddingZeros,
+			IV:        testMessage(8),
+			KDF:       customlib.KDFParams{Params: []byte{}},
+		},
+	}
+
+	for _, h := range headers {
+		data, err := h.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := customlib.ReadFileHeader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, h) {
+			t.Errorf("версия %d: прочитано %+v, ожидалось %+v", h.Version, got, h)
+		}
+	}
+}
+
+func TestFileHeaderRejectsUnknownVersion(t *testing.T) {
+	h := &customlib.FileHeader{Version: customlib.FileFormatVersion + 1, BlockSize: 16}
+	data, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := customlib.ReadFileHeader(bytes.NewReader(data)); !errors.Is(err, customlib.ErrUnsupportedVersion) {
+		t.Errorf("ожидалась ErrUnsupportedVersion, получено %v", err)
+	}
+}
+
+func TestContainerStoresModeParameters(t *testing.T) {
+	plaintext := testMessage(3000)
+
+	tests := []struct {
+		name      string
+		mode      customlib.CipherMode
+		encryptor []customlib.Option
+	}{
+		{"cfb8", customlib.ModeCFB, []customlib.Option{customlib.WithSegmentSize(8)}},
+		{"ofb8", customlib.ModeOFB, []customlib.Option{customlib.WithSegmentSize(8)}},
+		{"ctr-le", customlib.ModeCTR, []customlib.Option{customlib.WithCTRLayout(customlib.CTRLayout{Offset: 12, Width: 4, LittleEndian: true})}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			newContext := func(opts ...customlib.Option) *customlib.CryptoContext {
+				cipher, err := rijndael.NewAES()
+				if err != nil {
+					t.Fatal(err)
+				}
+				opts = append([]customlib.Option{customlib.WithMode(tc.mode), customlib.WithRandomIV()}, opts...)
+				ctx, err := customlib.NewCryptoContextWithOptions(keyA, cipher, opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return ctx
+			}
+
+			sealed := encryptContainer(t, newContext(tc.encryptor...), plaintext)
+			decrypted, err := decryptContainer(newContext(), sealed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Error("контекст по умолчанию не восстановил параметры режима из заголовка")
+			}
+		})
+	}
+}
+
+func TestContainerStoresSectorSize(t *testing.T) {
+	plaintext := testMessage(5000)
+	sealed := encryptContainer(t, newXTSContext(t, testMessage(32), 512), plaintext)
+
+	decrypted, err := decryptContainer(newXTSContext(t, testMessage(32), 4096), sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Error("размер сектора не восстановлен из заголовка")
+	}
+}
@@ -0,0 +1,73 @@
+package customlib
+
+import (
+	"errors"
+)
+
+var ErrCounterOverflow = errors.New("переполнение счётчика CTR")
+
+type CTRLayout struct {
+	Offset          int
+	Width           int
+	LittleEndian    bool
+	ErrorOnOverflow bool
+}
+
+var gcmCounterLayout = CTRLayout{Offset: 12, Width: 4}
+
+func (ctx *CryptoContext) SetCTRLayout(layout CTRLayout) error {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	err := layout.validate(ctx.blockSize)
+	if err != nil {
+		return err
+	}
+
+	ctx.ctrLayout = layout
+	return nil
+}
+
+func (ctx *CryptoContext) CTRLayout() CTRLayout {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.ctrLayout
+}
+
+func (l CTRLayout) validate(blockSize int) error {
+	if l.Offset < 0 || l.Width < 0 {
+		return errors.New("смещение и ширина счётчика не могут быть отрицательными")
+	}
+	if l.Offset+l.Width > blockSize || (l.Width == 0 && l.Offset >= blockSize) {
+		return errors.New("счётчик не помещается в блок")
+	}
+	return nil
+}
+
+func (l CTRLayout) field(counter []byte) []byte {
+	if l.Width == 0 {
+		return counter[l.Offset:]
+	}
+	return counter[l.Offset : l.Offset+l.Width]
+}
+
+func (l CTRLayout) add(counter []byte, n uint64) error {
+	field := l.field(counter)
+
+	for k := 0; k < len(field) && n != 0; k++ {
+		i := len(field) - 1 - k
+		if l.LittleEndian {
+			i = k
+		}
+
+		sum := uint64(field[i]) + n&0xFF
+		field[i] = byte(sum)
+		n = n>>8 + sum>>8
+	}
+
+	if n != 0 && l.ErrorOnOverflow {
+		return ErrCounterOverflow
+	}
+	return nil
+}
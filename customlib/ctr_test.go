@@ -0,0 +1,147 @@
+package customlib_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+
+	"iSL1/customlib"
+)
+
+func ctrKeystream(t *testing.T, ctx *customlib.CryptoContext, blocks int) ([]byte, error) {
+	t.Helper()
+	ciphertext, err := ctx.Encrypt(make([]byte, blocks*16-1))
+	if err != nil {
+		return nil, err
+	}
+	ciphertext[len(ciphertext)-1] ^= 0x01
+	return ciphertext, nil
+}
+
+func referenceKeystream(t *testing.T, counters ...string) []byte {
+	t.Helper()
+	block, err := aes.NewCipher(keyA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keystream []byte
+	for _, counter := range counters {
+		out := make([]byte, 16)
+		block.Encrypt(out, mustHex(t, counter))
+		keystream = append(keystream, out...)
+	}
+	return keystream
+}
+
+func newCTRContext(t *testing.T, iv string, layout customlib.CTRLayout) *customlib.CryptoContext {
+	t.Helper()
+	ctx := newAESContext(t, keyA, customlib.ModeCTR, mustHex(t, iv))
+	if err := ctx.SetCTRLayout(layout); err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func TestCTRLayoutCounters(t *testing.T) {
+	tests := []struct {
+		name     string
+		iv       string
+		layout   customlib.CTRLayout
+		counters []string
+	}{
+		{
+			"96/32",
+			"cafebabefacedbaddecaf888ffffffff",
+			customlib.CTRLayout{Offset: 12, Width: 4},
+			[]string{"cafebabefacedbaddecaf888ffffffff", "cafebabefacedbaddecaf88800000000", "cafebabefacedbaddecaf88800000001"},
+		},
+		{
+			"little-endian",
+			"ffff0000aaaaaaaaaaaaaaaaaaaaaaaa",
+			customlib.CTRLayout{Offset: 0, Width: 4, LittleEndian: true},
+			[]string{"ffff0000aaaaaaaaaaaaaaaaaaaaaaaa", "00000100aaaaaaaaaaaaaaaaaaaaaaaa", "01000100aaaaaaaaaaaaaaaaaaaaaaaa"},
+		},
+		{
+			"middle-field",
+			"1111111111111111000000ff22222222",
+			customlib.CTRLayout{Offset: 8, Width: 4},
+			[]string{"1111111111111111000000ff22222222", "11111111111111110000010022222222", "11111111111111110000010122222222"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := newCTRContext(t, tc.iv, tc.layout)
+			keystream, err := ctrKeystream(t, ctx, len(tc.counters))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := referenceKeystream(t, tc.counters...); !bytes.Equal(keystream, expected) {
+				t.Errorf("ключевой поток %x, ожидался %x", keystream, expected)
+			}
+		})
+	}
+}
+
+func TestCTRLayoutOverflow(t *testing.T) {
+	layout := customlib.CTRLayout{Offset: 12, Width: 4, ErrorOnOverflow: true}
+	ctx := newCTRContext(t, "000000000000000000000000fffffffe", layout)
+
+	if _, err := ctrKeystream(t, ctx, 2); err != nil {
+		t.Fatalf("два блока до переполнения: %v", err)
+	}
+	if _, err := ctrKeystream(t, ctx, 3); !errors.Is(err, customlib.ErrCounterOverflow) {
+		t.Errorf("ожидалась ErrCounterOverflow, получено %v", err)
+	}
+
+	layout.ErrorOnOverflow = false
+	ctx = newCTRContext(t, "000000000000000000000000fffffffe", layout)
+	if _, err := ctrKeystream(t, ctx, 3); err != nil {
+		t.Errorf("без ErrorOnOverflow счётчик должен переходить через ноль: %v", err)
+	}
+}
+
+func TestCTRDefaultLayoutMatchesStdlib(t *testing.T) {
+	block, err := aes.NewCipher(keyA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, iv := range []string{
+		"000102030405060708090a0b0c0d0e0f",
+		"0000000000000000fffffffffffffffe",
+		"fffffffffffffffffffffffffffffffe",
+	} {
+		ctx := newAESContext(t, keyA, customlib.ModeCTR, mustHex(t, iv))
+		keystream, err := ctrKeystream(t, ctx, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := make([]byte, 5*16)
+		cipher.NewCTR(block, mustHex(t, iv)).XORKeyStream(expected, expected)
+		if !bytes.Equal(keystream, expected) {
+			t.Errorf("IV %s: ключевой поток %x, ожидался %x", iv, keystream, expected)
+		}
+	}
+}
+
+func TestCTRLayoutValidation(t *testing.T) {
+	ctx := newAESContext(t, keyA, customlib.ModeCTR, make([]byte, 16))
+	for _, layout := range []customlib.CTRLayout{
+		{Offset: -1, Width: 4},
+		{Offset: 0, Width: -1},
+		{Offset: 12, Width: 8},
+		{Offset: 16, Width: 0},
+	} {
+		if err := ctx.SetCTRLayout(layout); err == nil {
+			t.Errorf("%+v: ожидалась ошибка", layout)
+		}
+	}
+	if ctx.CTRLayout() != (customlib.CTRLayout{}) {
+		t.Errorf("раскладка изменилась: %+v", ctx.CTRLayout())
+	}
+}
@@ -0,0 +1,105 @@
+package customlib
+
+import (
+	"errors"
+)
+
+func (ctx *CryptoContext) ciphertextStealing() bool {
+	return ctx.mode == ModeCBCCS1 || ctx.mode == ModeCBCCS2 || ctx.mode == ModeCBCCS3
+}
+
+func (ctx *CryptoContext) encryptCTS(data []byte) ([]byte, error) {
+	blockSize := ctx.blockSize
+	if len(data) < blockSize {
+		return nil, errors.New("данные короче одного блока")
+	}
+	if err := ctx.checkIV(); err != nil {
+		return nil, err
+	}
+
+	blocks := (len(data) + blockSize - 1) / blockSize
+	tail := len(data) - (blocks-1)*blockSize
+
+	padded := make([]byte, blocks*blockSize)
+	copy(padded, data)
+
+	mode := &cbcMode{ctx: ctx, prev: cloneBytes(ctx.iv)}
+	encrypted, err := mode.cryptBlocks(padded)
+	if err != nil {
+		return nil, err
+	}
+	if blocks == 1 {
+		return encrypted, nil
+	}
+
+	prefix := encrypted[:(blocks-2)*blockSize]
+	stolen := encrypted[(blocks-2)*blockSize : (blocks-2)*blockSize+tail]
+	last := encrypted[(blocks-1)*blockSize:]
+
+	result := make([]byte, 0, len(data))
+	result = append(result, prefix...)
+	if ctx.swapCTSBlocks(tail) {
+		result = append(result, last...)
+		return append(result, stolen...), nil
+	}
+	result = append(result, stolen...)
+	return append(result, last...), nil
+}
+
+func (ctx *CryptoContext) decryptCTS(data []byte) ([]byte, error) {
+	blockSize := ctx.blockSize
+	if len(data) < blockSize {
+		return nil, errors.New("данные короче одного блока")
+	}
+	if err := ctx.checkIV(); err != nil {
+		return nil, err
+	}
+
+	mode := &cbcMode{ctx: ctx, decrypt: true, prev: cloneBytes(ctx.iv)}
+
+	blocks := (len(data) + blockSize - 1) / blockSize
+	if blocks == 1 {
+		return mode.cryptBlocks(data)
+	}
+
+	tail := len(data) - (blocks-1)*blockSize
+	offset := (blocks - 2) * blockSize
+	prefix := data[:offset]
+
+	var stolen, last []byte
+	if ctx.swapCTSBlocks(tail) {
+		last = data[offset : offset+blockSize]
+		stolen = data[offset+blockSize:]
+	} else {
+		stolen = data[offset : offset+tail]
+		last = data[offset+tail:]
+	}
+
+	decryptedLast, err := ctx.decryptBlock(last)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := make([]byte, 0, (blocks-1)*blockSize)
+	ciphertext = append(ciphertext, prefix...)
+	ciphertext = append(ciphertext, stolen...)
+	ciphertext = append(ciphertext, decryptedLast[tail:]...)
+
+	result, err := mode.cryptBlocks(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(result, xorBlocks(decryptedLast[:tail], stolen)...), nil
+}
+
+func (ctx *CryptoContext) swapCTSBlocks(tail int) bool {
+	switch ctx.mode {
+	case ModeCBCCS2:
+		return tail != ctx.blockSize
+	case ModeCBCCS3:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,113 @@
+package customlib_test
+
+import (
+	"bytes"
+	"testing"
+
+	"iSL1/customlib"
+)
+
+var ctsPlaintext = []byte("I would like the General Gau's Chicken, please, and wonton soup.")
+
+var ctsVectors = []struct {
+	length int
+	cs3    string
+}{
+	{17, "c6353568f2bf8cb4d8a580362da7ff7f97"},
+	{31, "fc00783e0efdb2c1d445d4c8eff7ed2297687268d6ecccc0c07b25e25ecfe5"},
+	{32, "39312523a78662d5be7fcbcc98ebf5a897687268d6ecccc0c07b25e25ecfe584"},
+	{47, "97687268d6ecccc0c07b25e25ecfe584b3fffd940c16a18c1b5549d2f838029e39312523a78662d5be7fcbcc98ebf5"},
+	{48, "97687268d6ecccc0c07b25e25ecfe5849dad8bbb96c4cdc03bc103e1a194bbd839312523a78662d5be7fcbcc98ebf5a8"},
+	{64, "97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5a84807efe836ee89a526730dbc2f7bc8409dad8bbb96c4cdc03bc103e1a194bbd8"},
+}
+
+func newCTSContext(t *testing.T, mode customlib.CipherMode) *customlib.CryptoContext {
+	t.Helper()
+	return newAESContext(t, mustHex(t, "636869636b656e207465726979616b69"), mode, make([]byte, 16))
+}
+
+func cs1FromCS3(ciphertext []byte) []byte {
+	n := len(ciphertext)
+	tail := n % 16
+	if tail == 0 {
+		tail = 16
+	}
+	offset := n - 16 - tail
+
+	result := append([]byte(nil), ciphertext[:offset]...)
+	result = append(result, ciphertext[offset+16:]...)
+	return append(result, ciphertext[offset:offset+16]...)
+}
+
+func TestCTSVectors(t *testing.T) {
+	for _, tc := range ctsVectors {
+		cs3 := mustHex(t, tc.cs3)
+		cs1 := cs1FromCS3(cs3)
+		cs2 := cs3
+		if tc.length%16 == 0 {
+			cs2 = cs1
+		}
+
+		expected := map[customlib.CipherMode][]byte{
+			customlib.ModeCBCCS1: cs1,
+			customlib.ModeCBCCS2: cs2,
+			customlib.ModeCBCCS3: cs3,
+		}
+		for mode, want := range expected {
+			ctx := newCTSContext(t, mode)
+			plaintext := ctsPlaintext[:tc.length]
+
+			ciphertext, err := ctx.Encrypt(plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(ciphertext, want) {
+				t.Errorf("режим %d, длина %d: шифртекст %x, ожидался %x", mode, tc.length, ciphertext, want)
+			}
+
+			decrypted, err := ctx.Decrypt(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("режим %d, длина %d: расшифровано %q", mode, tc.length, decrypted)
+			}
+		}
+	}
+}
+
+func TestCTSPreservesLength(t *testing.T) {
+	for _, mode := range []customlib.CipherMode{customlib.ModeCBCCS1, customlib.ModeCBCCS2, customlib.ModeCBCCS3} {
+		ctx := newCTSContext(t, mode)
+		for n := 16; n < 70; n++ {
+			plaintext := testMessage(n)
+			ciphertext, err := ctx.Encrypt(plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ciphertext) != n {
+				t.Fatalf("режим %d: длина шифртекста %d, ожидалась %d", mode, len(ciphertext), n)
+			}
+
+			decrypted, err := ctx.Decrypt(ciphertext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Fatalf("режим %d, длина %d: расшифровка не совпала", mode, n)
+			}
+		}
+	}
+}
+
+func TestCTSRejectsShortInput(t *testing.T) {
+	for _, mode := range []customlib.CipherMode{customlib.ModeCBCCS1, customlib.ModeCBCCS2, customlib.ModeCBCCS3} {
+		ctx := newCTSContext(t, mode)
+		if _, err := ctx.Encrypt(testMessage(15)); err == nil {
+			t.Errorf("режим %d: ожидалась ошибка шифрования короткого блока", mode)
+		}
+		if _, err := ctx.Decrypt(testMessage(15)); err == nil {
+			t.Errorf("режим %d: ожидалась ошибка расшифрования короткого блока", mode)
+		}
+	}
+}
@@ -0,0 +1,508 @@
+package customlib
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+const fileChunkBlocks = 1024
+
+type fileChunk struct {
+	seq  int
+	data []byte
+	prev []byte
+	last bool
+}
+
+type ProgressFunc func(processed int64, total int64)
+
+type progressReader struct {
+	r         io.Reader
+	runCtx    context.Context
+	progress  ProgressFunc
+	processed int64
+	total     int64
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	if err := pr.runCtx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := pr.r.Read(p)
+	if n > 0 {
+		pr.processed += int64(n)
+		if pr.progress != nil {
+			pr.progress(pr.processed, pr.total)
+		}
+	}
+	return n, err
+}
+
+func (ctx *CryptoContext) EncryptFile(inputPath string, outputPath string) error {
+	return ctx.EncryptFileContext(context.Background(), inputPath, outputPath, nil)
+}
+
+func (ctx *CryptoContext) DecryptFile(inputPath string, outputPath string) error {
+	return ctx.DecryptFileContext(context.Background(), inputPath, outputPath, nil)
+}
+
+func (ctx *CryptoContext) EncryptFileContext(runCtx context.Context, inputPath string, outputPath string, progress ProgressFunc) error {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.processFile(runCtx, inputPath, outputPath, false, progress)
+}
+
+func (ctx *CryptoContext) DecryptFileContext(runCtx context.Context, inputPath string, outputPath string, progress ProgressFunc) error {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.processFile(runCtx, inputPath, outputPath, true, progress)
+}
+
+func (ctx *CryptoContext) EncryptStream(runCtx context.Context, r io.Reader, w io.Writer) error {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.encryptContainer(runCtx, &progressReader{r: r, runCtx: runCtx}, w)
+}
+
+func (ctx *CryptoContext) DecryptStream(runCtx context.Context, r io.Reader, w io.Writer) error {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	verified := false
+	if rs, ok := r.(io.ReadSeeker); ok {
+		start, err := rs.Seek(0, io.SeekCurrent)
+		if err == nil {
+			verified, err = ctx.verifyContainer(&progressReader{r: rs, runCtx: runCtx})
+			if err != nil {
+				return err
+			}
+			if _, err := rs.Seek(start, io.SeekStart); err != nil {
+				return err
+			}
+		}
+	}
+
+	return ctx.decryptContainer(runCtx, &progressReader{r: r, runCtx: runCtx}, w, verified)
+}
+
+func (ctx *CryptoContext) processFile(runCtx context.Context, inputPath string, outputPath string, decrypt bool, progress ProgressFunc) error {
+	if err := runCtx.Err(); err != nil {
+		return err
+	}
+
+	inputFile, err := os.Open(inputPath)
+	if err != nil {
+		return err
+	}
+	defer inputFile.Close()
+
+	info, err := inputFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	verified := false
+	if decrypt {
+		verified, err = ctx.verifyContainer(&progressReader{r: bufio.NewReader(inputFile), runCtx: runCtx})
+		if err != nil {
+			return err
+		}
+		if _, err := inputFile.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	outputFile, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+
+	reader := &progressReader{
+		r:        bufio.NewReader(inputFile),
+		runCtx:   runCtx,
+		progress: progress,
+		total:    info.Size(),
+	}
+	writer := bufio.NewWriter(outputFile)
+
+	if decrypt {
+		err = ctx.decryptContainer(runCtx, reader, writer, verified)
+	} else {
+		err = ctx.encryptContainer(runCtx, reader, writer)
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	closeErr := outputFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(outputPath)
+		if runCtx.Err() != nil {
+			return runCtx.Err()
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (ctx *CryptoContext) encryptContainer(runCtx context.Context, r io.Reader, w io.Writer) error {
+	if ctx.autoIV {
+		iv, err := ctx.freshIV()
+		if err != nil {
+			return err
+		}
+		ctx = ctx.withIV(iv)
+	}
+
+	header, err := ctx.fileHeader().MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	mac, err := ctx.newFileMAC()
+	if err != nil {
+		return err
+	}
+
+	out := w
+	if mac != nil {
+		out = io.MultiWriter(w, mac)
+	}
+
+	_, err = out.Write(header)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.processStream(runCtx, r, out, false)
+	if err != nil || mac == nil {
+		return err
+	}
+
+	_, err = w.Write(mac.Sum(nil))
+	return err
+}
+
+func (ctx *CryptoContext) decryptContainer(runCtx context.Context, r io.Reader, w io.Writer, verified bool) error {
+	header, err := ReadFileHeader(r)
+	if err != nil {
+		return err
+	}
+
+	fileCtx, err := ctx.forFileHeader(header)
+	if err != nil {
+		return err
+	}
+
+	mac, err := fileCtx.newFileMAC()
+	if err != nil {
+		return err
+	}
+	if mac == nil {
+		return fileCtx.processStream(runCtx, r, w, true)
+	}
+
+	headerBytes, err := header.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	mac.Write(headerBytes)
+
+	var plaintext bytes.Buffer
+	out := w
+	if !verified {
+		out = &plaintext
+	}
+
+	mr := &macReader{r: r, mac: mac}
+	err = fileCtx.processStream(runCtx, mr, out, true)
+	if runCtx.Err() != nil {
+		return runCtx.Err()
+	}
+
+	_, drainErr := io.Copy(io.Discard, mr)
+	if drainErr != nil {
+		if err == nil {
+			err = drainErr
+		}
+		return err
+	}
+	if !mr.valid() {
+		return ErrAuthentication
+	}
+	if err != nil || verified {
+		return err
+	}
+
+	_, err = w.Write(plaintext.Bytes())
+	return err
+}
+
+func (ctx *CryptoContext) processStream(runCtx context.Context, r io.Reader, w io.Writer, decrypt bool) error {
+	if ctx.mode == ModeXTS {
+		return ctx.processSectors(runCtx, r, w, decrypt)
+	}
+
+	if ctx.wholeMessageMode() {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		process := ctx.encrypt
+		if decrypt {
+			process = ctx.decrypt
+		}
+
+		result, err := process(data)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(result)
+		return err
+	}
+
+	if ctx.parallelFileMode(decrypt) {
+		return ctx.processParallel(runCtx, r, w, decrypt)
+	}
+
+	if decrypt {
+		reader, err := newDecryptReader(r, ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(w, reader)
+		return err
+	}
+
+	writer, err := newEncryptWriter(w, ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, r)
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+func (ctx *CryptoContext) parallelFileMode(decrypt bool) bool {
+	switch ctx.mode {
+	case ModeECB, ModeCTR:
+		return true
+	case ModeCBC, ModeCFB:
+		return decrypt
+	default:
+		return false
+	}
+}
+
+func (ctx *CryptoContext) processParallel(parentCtx context.Context, r io.Reader, w io.Writer, decrypt bool) error {
+	if ctx.mode != ModeECB {
+		if err := ctx.checkIV(); err != nil {
+			return err
+		}
+	}
+
+	workers := ctx.workerCount()
+
+	runCtx, cancel := context.WithCancel(parentCtx)
+	defer cancel()
+
+	var firstErr error
+	var errOnce sync.Once
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	jobs := make(chan fileChunk, workers)
+	results := make(chan fileChunk)
+	tokens := make(chan struct{}, 2*workers)
+
+	var readerWG sync.WaitGroup
+	readerWG.Add(1)
+	go func() {
+		defer readerWG.Done()
+		defer close(jobs)
+
+		err := ctx.readChunks(runCtx, r, jobs, tokens)
+		if err != nil {
+			fail(err)
+		}
+	}()
+
+	var workersWG sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		workersWG.Add(1)
+		go func() {
+			defer workersWG.Done()
+
+			for job := range jobs {
+				if runCtx.Err() != nil {
+					continue
+				}
+
+				output, err := ctx.cryptChunk(job, decrypt)
+				if err != nil {
+					fail(err)
+					continue
+				}
+
+				job.data = output
+				select {
+				case results <- job:
+				case <-runCtx.Done():
+				}
+			}
+		}()
+	}
+
+	go func() {
+		workersWG.Wait()
+		close(results)
+	}()
+
+	pending := make(map[int]fileChunk)
+	next := 0
+	for result := range results {
+		pending[result.seq] = result
+
+		for {
+			chunk, ok := pending[next]
+			if !ok || runCtx.Err() != nil {
+				break
+			}
+			delete(pending, next)
+
+			_, err := w.Write(chunk.data)
+			if err != nil {
+				fail(err)
+				break
+			}
+
+			next++
+			<-tokens
+		}
+	}
+
+	readerWG.Wait()
+	if firstErr == nil && parentCtx.Err() != nil {
+		return parentCtx.Err()
+	}
+	return firstErr
+}
+
+func (ctx *CryptoContext) readChunks(runCtx context.Context, r io.Reader, jobs chan<- fileChunk, tokens chan struct{}) error {
+	chunkSize := ctx.blockSize * fileChunkBlocks
+	prev := cloneBytes(ctx.iv)
+
+	current, currentDone, err := readChunk(r, chunkSize)
+	for seq := 0; ; seq++ {
+		if err != nil {
+			return err
+		}
+
+		var next []byte
+		nextDone := true
+		if !currentDone {
+			next, nextDone, err = readChunk(r, chunkSize)
+			if err != nil {
+				return err
+			}
+		}
+
+		last := currentDone || (nextDone && len(next) == 0)
+
+		select {
+		case tokens <- struct{}{}:
+		case <-runCtx.Done():
+			return nil
+		}
+
+		select {
+		case jobs <- fileChunk{seq: seq, data: current, prev: prev, last: last}:
+		case <-runCtx.Done():
+			return nil
+		}
+
+		if last {
+			return nil
+		}
+
+		prev = current[len(current)-ctx.blockSize:]
+		current, currentDone = next, nextDone
+	}
+}
+
+func readChunk(r io.Reader, chunkSize int) ([]byte, bool, error) {
+	buffer := make([]byte, chunkSize)
+	n, err := io.ReadFull(r, buffer)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return buffer[:n], true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return buffer, false, nil
+}
+
+func (ctx *CryptoContext) cryptChunk(chunk fileChunk, decrypt bool) ([]byte, error) {
+	data := chunk.data
+	if !decrypt && chunk.last {
+		var err error
+		data, err = ctx.applyPadding(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var mode blockMode
+	switch ctx.mode {
+	case ModeECB:
+		mode = &ecbMode{ctx: ctx, decrypt: decrypt, sequential: true}
+	case ModeCTR:
+		counter := cloneBytes(ctx.iv)
+		err := ctx.ctrLayout.add(counter, uint64(chunk.seq)*fileChunkBlocks)
+		if err != nil {
+			return nil, err
+		}
+		mode = &ctrMode{ctx: ctx, counter: counter, layout: ctx.ctrLayout}
+	case ModeCBC:
+		mode = &cbcMode{ctx: ctx, decrypt: decrypt, prev: cloneBytes(chunk.prev)}
+	case ModeCFB:
+		mode = &cfbMode{ctx: ctx, decrypt: decrypt, register: cloneBytes(chunk.prev)}
+	default:
+		return nil, errors.New("режим шифрования не поддерживает параллельную обработку")
+	}
+
+	output, err := mode.cryptBlocks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if decrypt && chunk.last {
+		return ctx.removePadding(output)
+	}
+	return output, nil
+}
@@ -0,0 +1,198 @@
+package customlib_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/rijndael"
+)
+
+var errBlockFailed = errors.New("сбой шифрования блока")
+
+type failingCipher struct {
+	customlib.BlockCipher
+	calls  atomic.Int64
+	failAt int64
+}
+
+func (c *failingCipher) EncryptBlock(block []byte) ([]byte, error) {
+	if c.calls.Add(1) >= c.failAt {
+		return nil, errBlockFailed
+	}
+	return c.BlockCipher.EncryptBlock(block)
+}
+
+func newFileContext(t *testing.T, cipher customlib.BlockCipher, mode customlib.CipherMode, workers int) *customlib.CryptoContext {
+	t.Helper()
+	if cipher == nil {
+		aes, err := rijndael.NewAES()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cipher = aes
+	}
+
+	opts := []customlib.Option{customlib.WithMode(mode), customlib.WithWorkers(workers)}
+	if mode != customlib.ModeECB {
+		opts = append(opts, customlib.WithIV(testMessage(16)))
+	}
+	ctx, err := customlib.NewCryptoContextWithOptions(keyA, cipher, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func writeTestFile(t *testing.T, size int) (string, []byte) {
+	t.Helper()
+	data := testMessage(size)
+	path := filepath.Join(t.TempDir(), "input.bin")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path, data
+}
+
+func TestFileParallelDeterministic(t *testing.T) {
+	input, plaintext := writeTestFile(t, 48*1024+7)
+	dir := t.TempDir()
+
+	for _, mode := range []customlib.CipherMode{customlib.ModeECB, customlib.ModeCTR, customlib.ModeCBC} {
+		var reference []byte
+		for _, workers := range []int{1, 4} {
+			ctx := newFileContext(t, nil, mode, workers)
+			encrypted := filepath.Join(dir, "encrypted.bin")
+			decrypted := filepath.Join(dir, "decrypted.bin")
+
+			if err := ctx.EncryptFile(input, encrypted); err != nil {
+				t.Fatal(err)
+			}
+			output, err := os.ReadFile(encrypted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reference == nil {
+				reference = output
+			} else if !bytes.Equal(output, reference) {
+				t.Errorf("режим %d: результат с %d потоками отличается от однопоточного", mode, workers)
+			}
+
+			if err := ctx.DecryptFile(encrypted, decrypted); err != nil {
+				t.Fatal(err)
+			}
+			roundTrip, err := os.ReadFile(decrypted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(roundTrip, plaintext) {
+				t.Errorf("режим %d, %d потоков: расшифровка не совпала", mode, workers)
+			}
+		}
+
+		header, err := customlib.ReadFileHeader(bytes.NewReader(reference))
+		if err != nil {
+			t.Fatal(err)
+		}
+		headerBytes, err := header.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := newFileContext(t, nil, mode, 1).Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(reference[len(headerBytes):], expected) {
+			t.Errorf("режим %d: тело контейнера не совпало с Encrypt", mode)
+		}
+	}
+}
+
+func TestFileProgress(t *testing.T) {
+	input, plaintext := writeTestFile(t, 100*1024)
+	output := filepath.Join(t.TempDir(), "output.bin")
+	ctx := newFileContext(t, nil, customlib.ModeCTR, 4)
+
+	var calls int
+	var last int64
+	err := ctx.EncryptFileContext(context.Background(), input, output, func(processed int64, total int64) {
+		calls++
+		if processed < last {
+			t.Errorf("прогресс уменьшился: %d после %d", processed, last)
+		}
+		if total != int64(len(plaintext)) {
+			t.Errorf("total %d, ожидалось %d", total, len(plaintext))
+		}
+		last = processed
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls == 0 {
+		t.Fatal("функция прогресса не вызывалась")
+	}
+	if last != int64(len(plaintext)) {
+		t.Errorf("итоговый прогресс %d, ожидалось %d", last, len(plaintext))
+	}
+}
+
+func TestFileCancellation(t *testing.T) {
+	input, _ := writeTestFile(t, 256*1024)
+	output := filepath.Join(t.TempDir(), "output.bin")
+
+	for _, mode := range []customlib.CipherMode{customlib.ModeCTR, customlib.ModeCBC} {
+		ctx := newFileContext(t, nil, mode, 4)
+		runCtx, cancel := context.WithCancel(context.Background())
+
+		err := ctx.EncryptFileContext(runCtx, input, output, func(processed int64, total int64) {
+			if processed > total/4 {
+				cancel()
+			}
+		})
+		cancel()
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("режим %d: ожидалась context.Canceled, получено %v", mode, err)
+		}
+		if _, err := os.Stat(output); !os.IsNotExist(err) {
+			t.Errorf("режим %d: выходной файл не удалён", mode)
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx := newFileContext(t, nil, customlib.ModeCTR, 4)
+	if err := ctx.EncryptFileContext(runCtx, input, output, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("ожидалась context.Canceled, получено %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("выходной файл создан для отменённого контекста")
+	}
+}
+
+func TestFileWorkerErrorStopsOthers(t *testing.T) {
+	const blocks = 64 * 1024
+	input, _ := writeTestFile(t, blocks*16)
+	output := filepath.Join(t.TempDir(), "output.bin")
+
+	aes, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher := &failingCipher{BlockCipher: aes, failAt: 1500}
+	ctx := newFileContext(t, cipher, customlib.ModeECB, 4)
+
+	if err := ctx.EncryptFile(input, output); !errors.Is(err, errBlockFailed) {
+		t.Fatalf("ожидалась ошибка потока, получено %v", err)
+	}
+	if calls := cipher.calls.Load(); calls >= blocks/2 {
+		t.Errorf("после ошибки зашифровано %d блоков из %d", calls, blocks)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("выходной файл не удалён после ошибки")
+	}
+}
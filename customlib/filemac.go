@@ -0,0 +1,147 @@
+package customlib
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+)
+
+var fileMACLabel = []byte("ISLC-MAC")
+
+func (ctx *CryptoContext) SetFileMAC(id MACID) error {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	if err := checkFileMAC(id); err != nil {
+		return err
+	}
+
+	ctx.fileMAC = id
+	return nil
+}
+
+func (ctx *CryptoContext) FileMAC() MACID {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.fileMAC
+}
+
+func checkFileMAC(id MACID) error {
+	switch id {
+	case MACNone, MACHMACSHA256:
+		return nil
+	default:
+		return errors.New("неподдерживаемый алгоритм MAC")
+	}
+}
+
+func (ctx *CryptoContext) newFileMAC() (hash.Hash, error) {
+	switch ctx.fileMAC {
+	case MACNone:
+		return nil, nil
+	case MACHMACSHA256:
+		derive := hmac.New(sha256.New, ctx.key)
+		derive.Write(fileMACLabel)
+		return hmac.New(sha256.New, derive.Sum(nil)), nil
+	default:
+		return nil, errors.New("неподдерживаемый алгоритм MAC")
+	}
+}
+
+func (ctx *CryptoContext) verifyContainer(r io.Reader) (bool, error) {
+	header, err := ReadFileHeader(r)
+	if err != nil {
+		return false, err
+	}
+
+	fileCtx, err := ctx.forFileHeader(header)
+	if err != nil {
+		return false, err
+	}
+
+	mac, err := fileCtx.newFileMAC()
+	if err != nil || mac == nil {
+		return false, err
+	}
+
+	headerBytes, err := header.MarshalBinary()
+	if err != nil {
+		return false, err
+	}
+	mac.Write(headerBytes)
+
+	mr := &macReader{r: r, mac: mac}
+	if _, err := io.Copy(io.Discard, mr); err != nil {
+		return false, err
+	}
+	if !mr.valid() {
+		return false, ErrAuthentication
+	}
+	return true, nil
+}
+
+type macReader struct {
+	r    io.Reader
+	mac  hash.Hash
+	tail []byte
+	err  error
+}
+
+func (mr *macReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	size := mr.mac.Size()
+	for {
+		if mr.err != nil {
+			return 0, mr.err
+		}
+
+		buffer := make([]byte, len(mr.tail)+len(p))
+		copy(buffer, mr.tail)
+		n, err := mr.r.Read(buffer[len(mr.tail):])
+		buffer = buffer[:len(mr.tail)+n]
+		mr.err = err
+
+		ready := len(buffer) - size
+		if ready <= 0 {
+			mr.tail = buffer
+			continue
+		}
+
+		copy(p, buffer[:ready])
+		mr.mac.Write(buffer[:ready])
+		mr.tail = buffer[ready:]
+		return ready, nil
+	}
+}
+
+func (mr *macReader) valid() bool {
+	return len(mr.tail) == mr.mac.Size() && hmac.Equal(mr.mac.Sum(nil), mr.tail)
+}
+
+func verifyFileMAC(mac hash.Hash, header []byte, r io.ReaderAt, size int64) (int64, error) {
+	tagSize := int64(mac.Size())
+	if size < tagSize {
+		return 0, ErrAuthentication
+	}
+
+	tag := make([]byte, tagSize)
+	if _, err := r.ReadAt(tag, size-tagSize); err != nil {
+		return 0, err
+	}
+
+	mac.Write(header)
+	if _, err := io.Copy(mac, io.NewSectionReader(r, 0, size-tagSize)); err != nil {
+		return 0, err
+	}
+	if !hmac.Equal(mac.Sum(nil), tag) {
+		return 0, ErrAuthentication
+	}
+
+	return size - tagSize, nil
+}
@@ -0,0 +1,214 @@
+package customlib_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/rijndael"
+)
+
+func newMACContext(t *testing.T, mode customlib.CipherMode, mac customlib.MACID) *customlib.CryptoContext {
+	t.Helper()
+	cipher, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := customlib.NewCryptoContextWithOptions(testMessage(16), cipher, customlib.WithMode(mode), customlib.WithRandomIV(), customlib.WithFileMAC(mac))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func encryptContainer(t *testing.T, ctx *customlib.CryptoContext, plaintext []byte) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if err := ctx.EncryptStream(context.Background(), bytes.NewReader(plaintext), &out); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func decryptContainer(ctx *customlib.CryptoContext, data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	err := ctx.DecryptStream(context.Background(), bytes.NewReader(data), &out)
+	return out.Bytes(), err
+}
+
+func TestFileMACRoundTrip(t *testing.T) {
+	plaintext := testMessage(5000)
+
+	for _, mode := range []customlib.CipherMode{customlib.ModeCBC, customlib.ModeCTR, customlib.ModeOFB, customlib.ModeCBCCS3} {
+		ctx := newMACContext(t, mode, customlib.MACHMACSHA256)
+		sealed := encryptContainer(t, ctx, plaintext)
+
+		header, err := customlib.ReadFileHeader(bytes.NewReader(sealed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.MAC != customlib.MACHMACSHA256 {
+			t.Fatalf("режим %d: MAC в заголовке %d", mode, header.MAC)
+		}
+
+		decrypted, err := decryptContainer(ctx, sealed)
+		if err != nil {
+			t.Fatalf("режим %d: %v", mode, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("режим %d: расшифрованные данные не совпадают", mode)
+		}
+	}
+}
+
+func TestFileMACTamper(t *testing.T) {
+	ctx := newMACContext(t, customlib.ModeCTR, customlib.MACHMACSHA256)
+	sealed := encryptContainer(t, ctx, testMessage(300))
+
+	for i := 0; i < len(sealed); i++ {
+		tampered := append([]byte(nil), sealed...)
+		tampered[i] ^= 0x80
+		if _, err := decryptContainer(ctx, tampered); err == nil {
+			t.Fatalf("байт %d: изменение не обнаружено", i)
+		}
+	}
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-40] ^= 0x01
+	if _, err := decryptContainer(ctx, tampered); !errors.Is(err, customlib.ErrAuthentication) {
+		t.Errorf("ожидалась ErrAuthentication, получено %v", err)
+	}
+
+	if _, err := decryptContainer(ctx, sealed[:len(sealed)-1]); !errors.Is(err, customlib.ErrAuthentication) {
+		t.Errorf("усечение: ожидалась ErrAuthentication, получено %v", err)
+	}
+}
+
+func TestFileMACDowngrade(t *testing.T) {
+	ctx := newMACContext(t, customlib.ModeCBC, customlib.MACHMACSHA256)
+	plain := newMACContext(t, customlib.ModeCBC, customlib.MACNone)
+
+	sealed := encryptContainer(t, plain, testMessage(100))
+	if _, err := decryptContainer(ctx, sealed); !errors.Is(err, customlib.ErrAuthentication) {
+		t.Errorf("ожидалась ErrAuthentication для файла без MAC, получено %v", err)
+	}
+
+	if _, err := decryptContainer(plain, encryptContainer(t, ctx, testMessage(100))); err != nil {
+		t.Errorf("файл с MAC должен расшифровываться по заголовку: %v", err)
+	}
+}
+
+func TestFileMACRemovesOutput(t *testing.T) {
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "plain")
+	sealedPath := filepath.Join(dir, "sealed")
+	outPath := filepath.Join(dir, "out")
+
+	if err := os.WriteFile(plainPath, testMessage(20000), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := newMACContext(t, customlib.ModeCBC, customlib.MACHMACSHA256)
+	if err := ctx.EncryptFile(plainPath, sealedPath); err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := os.ReadFile(sealedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed[len(sealed)/2] ^= 0x01
+	if err := os.WriteFile(sealedPath, sealed, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ctx.DecryptFile(sealedPath, outPath); !errors.Is(err, customlib.ErrAuthentication) {
+		t.Fatalf("ожидалась ErrAuthentication, получено %v", err)
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Error("выходной файл не удалён после ошибки аутентификации")
+	}
+}
+
+func TestFileMACSeekable(t *testing.T) {
+	ctx := newMACContext(t, customlib.ModeCTR, customlib.MACHMACSHA256)
+	plaintext := testMessage(1000)
+	sealed := encryptContainer(t, ctx, plaintext)
+
+	sr, err := ctx.OpenSeekable(bytes.NewReader(sealed), int64(len(sealed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sr.Size() != int64(len(plaintext)) {
+		t.Fatalf("размер %d, ожидался %d", sr.Size(), len(plaintext))
+	}
+
+	part := make([]byte, 100)
+	if _, err := sr.ReadAt(part, 500); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(part, plaintext[500:600]) {
+		t.Error("данные по смещению 500 не совпадают")
+	}
+
+	sealed[len(sealed)/2] ^= 0x01
+	if _, err := ctx.OpenSeekable(bytes.NewReader(sealed), int64(len(sealed))); !errors.Is(err, customlib.ErrAuthentication) {
+		t.Errorf("ожидалась ErrAuthentication, получено %v", err)
+	}
+}
+
+type cancelAtEOF struct {
+	r      io.Reader
+	cancel context.CancelFunc
+}
+
+func (c *cancelAtEOF) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if err == io.EOF {
+		c.cancel()
+	}
+	return n, err
+}
+
+func TestFileMACNoPlaintextBeforeVerification(t *testing.T) {
+	ctx := newMACContext(t, customlib.ModeCBC, customlib.MACHMACSHA256)
+	sealed := encryptContainer(t, ctx, testMessage(5000))
+	sealed[len(sealed)/2] ^= 0x01
+
+	readers := map[string]io.Reader{
+		"seekable": bytes.NewReader(sealed),
+		"stream":   struct{ io.Reader }{bytes.NewReader(sealed)},
+	}
+	for name, r := range readers {
+		var out bytes.Buffer
+		err := ctx.DecryptStream(context.Background(), r, &out)
+		if !errors.Is(err, customlib.ErrAuthentication) {
+			t.Errorf("%s: ожидалась ErrAuthentication, получено %v", name, err)
+		}
+		if out.Len() != 0 {
+			t.Errorf("%s: до проверки тега записано %d байт", name, out.Len())
+		}
+	}
+}
+
+func TestFileMACCancelledAfterDecrypt(t *testing.T) {
+	ctx := newMACContext(t, customlib.ModeCBC, customlib.MACHMACSHA256)
+	sealed := encryptContainer(t, ctx, testMessage(500))
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out bytes.Buffer
+	err := ctx.DecryptStream(runCtx, &cancelAtEOF{r: bytes.NewReader(sealed), cancel: cancel}, &out)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ожидалась context.Canceled, получено %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("после отмены записано %d байт", out.Len())
+	}
+}
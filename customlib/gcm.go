@@ -0,0 +1,175 @@
+package customlib
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	gcmBlockSize = 16
+	gcmTagSize   = 16
+)
+
+var ErrAuthentication = errors.New("ошибка аутентификации: тег не совпадает")
+
+func (ctx *CryptoContext) EncryptAEAD(data []byte, additionalData []byte) ([]byte, error) {
+	if ctx.mode != ModeGCM {
+		return nil, errors.New("режим шифрования не поддерживает связанные данные")
+	}
+
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	if ctx.autoIV {
+		return ctx.sealWithFreshIV(data, func(msgCtx *CryptoContext, data []byte) ([]byte, error) {
+			return msgCtx.sealGCM(data, additionalData)
+		})
+	}
+	return ctx.sealGCM(data, additionalData)
+}
+
+func (ctx *CryptoContext) DecryptAEAD(data []byte, additionalData []byte) ([]byte, error) {
+	if ctx.mode != ModeGCM {
+		return nil, errors.New("режим шифрования не поддерживает связанные данные")
+	}
+
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	if ctx.autoIV {
+		return ctx.openWithPrefixedIV(data, func(msgCtx *CryptoContext, data []byte) ([]byte, error) {
+			return msgCtx.openGCM(data, additionalData)
+		})
+	}
+	return ctx.openGCM(data, additionalData)
+}
+
+func (ctx *CryptoContext) sealGCM(data []byte, additionalData []byte) ([]byte, error) {
+	hashKey, j0, err := ctx.prepareGCM()
+	if err != nil {
+		return nil, err
+	}
+
+	counter := make([]byte, gcmBlockSize)
+	copy(counter, j0)
+	gcmCounterLayout.add(counter, 1)
+
+	ciphertext, err := ctx.xorKeyStream(data, counter, gcmCounterLayout)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := ctx.gcmTag(hashKey, j0, additionalData, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ciphertext, tag...), nil
+}
+
+func (ctx *CryptoContext) openGCM(data []byte, additionalData []byte) ([]byte, error) {
+	if len(data) < gcmTagSize {
+		return nil, ErrAuthentication
+	}
+
+	hashKey, j0, err := ctx.prepareGCM()
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := data[:len(data)-gcmTagSize]
+	receivedTag := data[len(data)-gcmTagSize:]
+
+	expectedTag, err := ctx.gcmTag(hashKey, j0, additionalData, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(expectedTag, receivedTag) != 1 {
+		return nil, ErrAuthentication
+	}
+
+	counter := make([]byte, gcmBlockSize)
+	copy(counter, j0)
+	gcmCounterLayout.add(counter, 1)
+
+	return ctx.xorKeyStream(ciphertext, counter, gcmCounterLayout)
+}
+
+func (ctx *CryptoContext) prepareGCM() ([]byte, []byte, error) {
+	if ctx.blockSize != gcmBlockSize {
+		return nil, nil, errors.New("режим GCM требует блочный шифр с размером блока 128 бит")
+	}
+	if len(ctx.iv) == 0 {
+		return nil, nil, errors.New("неверный вектор инициализации (IV)")
+	}
+
+	hashKey, err := ctx.encryptBlock(make([]byte, gcmBlockSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	j0 := make([]byte, gcmBlockSize)
+	if len(ctx.iv) == 12 {
+		copy(j0, ctx.iv)
+		j0[gcmBlockSize-1] = 1
+	} else {
+		lengths := make([]byte, gcmBlockSize)
+		binary.BigEndian.PutUint64(lengths[8:], uint64(len(ctx.iv))*8)
+		j0 = ghash(hashKey, ctx.iv, lengths)
+	}
+
+	return hashKey, j0, nil
+}
+
+func (ctx *CryptoContext) gcmTag(hashKey, j0, additionalData, ciphertext []byte) ([]byte, error) {
+	lengths := make([]byte, gcmBlockSize)
+	binary.BigEndian.PutUint64(lengths[:8], uint64(len(additionalData))*8)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(ciphertext))*8)
+
+	s := ghash(hashKey, additionalData, ciphertext, lengths)
+
+	encryptedJ0, err := ctx.encryptBlock(j0)
+	if err != nil {
+		return nil, err
+	}
+
+	return xorBlocks(s, encryptedJ0), nil
+}
+
+func ghash(hashKey []byte, parts ...[]byte) []byte {
+	y := make([]byte, gcmBlockSize)
+	for _, part := range parts {
+		for i := 0; i < len(part); i += gcmBlockSize {
+			block := make([]byte, gcmBlockSize)
+			copy(block, part[i:min(i+gcmBlockSize, len(part))])
+			y = gfMul128(xorBlocks(y, block), hashKey)
+		}
+	}
+	return y
+}
+
+func gfMul128(x, y []byte) []byte {
+	z := make([]byte, gcmBlockSize)
+	v := make([]byte, gcmBlockSize)
+	copy(v, y)
+
+	for i := 0; i < 128; i++ {
+		if x[i/8]&(0x80>>(i%8)) != 0 {
+			for j := range z {
+				z[j] ^= v[j]
+			}
+		}
+
+		lsb := v[gcmBlockSize-1] & 1
+		for j := gcmBlockSize - 1; j > 0; j-- {
+			v[j] = v[j]>>1 | v[j-1]<<7
+		}
+		v[0] >>= 1
+		if lsb != 0 {
+			v[0] ^= 0xE1
+		}
+	}
+
+	return z
+}
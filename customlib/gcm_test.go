@@ -0,0 +1,177 @@
+package customlib_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/rijndael"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func newGCMContext(t *testing.T, key, iv []byte) *customlib.CryptoContext {
+	t.Helper()
+	aesCipher, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := customlib.NewCryptoContextWithCipher(key, customlib.ModeGCM, customlib.PaddingZeros, iv, aesCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+const (
+	gcmKey       = "feffe9928665731c6d6a8f9467308308"
+	gcmPlaintext = "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39"
+	gcmAAD       = "feedfacedeadbeeffeedfacedeadbeefabaddad2"
+)
+
+func TestGCMVectors(t *testing.T) {
+	tests := []struct {
+		name                    string
+		key, iv, plaintext, aad string
+		ciphertext, tag         string
+	}{
+		{
+			name: "case1",
+			key:  "00000000000000000000000000000000",
+			iv:   "000000000000000000000000",
+			tag:  "58e2fccefa7e3061367f1d57a4e7455a",
+		},
+		{
+			name:       "case2",
+			key:        "00000000000000000000000000000000",
+			iv:         "000000000000000000000000",
+			plaintext:  "00000000000000000000000000000000",
+			ciphertext: "0388dace60b6a392f328c2b971b2fe78",
+			tag:        "ab6e47d42cec13bdf53a67b21257bddf",
+		},
+		{
+			name:       "case4",
+			key:        gcmKey,
+			iv:         "cafebabefacedbaddecaf888",
+			plaintext:  gcmPlaintext,
+			aad:        gcmAAD,
+			ciphertext: "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091",
+			tag:        "5bc94fbc3221a5db94fae95ae7121a47",
+		},
+		{
+			name:       "case5",
+			key:        gcmKey,
+			iv:         "cafebabefacedbad",
+			plaintext:  gcmPlaintext,
+			aad:        gcmAAD,
+			ciphertext: "61353b4c2806934a777ff51fa22a4755699b2a714fcdc6f83766e5f97b6c742373806900e49f24b22b097544d4896b424989b5e1ebac0f07c23f4598",
+			tag:        "3612d2e79e3b0785561be14aaca2fccb",
+		},
+		{
+			name:       "case6",
+			key:        gcmKey,
+			iv:         "9313225df88406e555909c5aff5269aa6a7a9538534f7da1e4c303d2a318a728c3c0c95156809539fcf0e2429a6b525416aedbf5a0de6a57a637b39b",
+			plaintext:  gcmPlaintext,
+			aad:        gcmAAD,
+			ciphertext: "8ce24998625615b603a033aca13fb894be9112a5c3a211a8ba262a3cca7e2ca701e4a9a4fba43c90ccdcb281d48c7c6fd62875d2aca417034c34aee5",
+			tag:        "619cc5aefffe0bfa462af43c1699d050",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := newGCMContext(t, mustHex(t, tc.key), mustHex(t, tc.iv))
+			plaintext := mustHex(t, tc.plaintext)
+			aad := mustHex(t, tc.aad)
+			expected := mustHex(t, tc.ciphertext+tc.tag)
+
+			sealed, err := ctx.EncryptAEAD(plaintext, aad)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(sealed, expected) {
+				t.Errorf("шифртекст %x, ожидался %x", sealed, expected)
+			}
+
+			opened, err := ctx.DecryptAEAD(sealed, aad)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Errorf("расшифровано %x, ожидалось %x", opened, plaintext)
+			}
+		})
+	}
+}
+
+func TestGCMAdditionalDataOnly(t *testing.T) {
+	key := mustHex(t, gcmKey)
+	iv := mustHex(t, "cafebabefacedbaddecaf888")
+	aad := mustHex(t, gcmAAD)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := reference.Seal(nil, iv, nil, aad)
+
+	ctx := newGCMContext(t, key, iv)
+	tag, err := ctx.EncryptAEAD(nil, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tag, expected) {
+		t.Errorf("тег %x, ожидался %x", tag, expected)
+	}
+
+	opened, err := ctx.DecryptAEAD(tag, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opened) != 0 {
+		t.Errorf("расшифровано %x, ожидались пустые данные", opened)
+	}
+}
+
+func TestGCMTamper(t *testing.T) {
+	ctx := newGCMContext(t, mustHex(t, gcmKey), mustHex(t, "cafebabefacedbaddecaf888"))
+	aad := mustHex(t, gcmAAD)
+
+	sealed, err := ctx.EncryptAEAD(mustHex(t, gcmPlaintext), aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, i := range []int{0, len(sealed) / 2, len(sealed) - 1} {
+		tampered := append([]byte(nil), sealed...)
+		tampered[i] ^= 0x01
+		if _, err := ctx.DecryptAEAD(tampered, aad); !errors.Is(err, customlib.ErrAuthentication) {
+			t.Errorf("байт %d: ожидалась ErrAuthentication, получено %v", i, err)
+		}
+	}
+
+	tamperedAAD := append([]byte(nil), aad...)
+	tamperedAAD[0] ^= 0x01
+	if _, err := ctx.DecryptAEAD(sealed, tamperedAAD); !errors.Is(err, customlib.ErrAuthentication) {
+		t.Errorf("связанные данные: ожидалась ErrAuthentication, получено %v", err)
+	}
+
+	if _, err := ctx.DecryptAEAD(sealed[:8], aad); !errors.Is(err, customlib.ErrAuthentication) {
+		t.Errorf("короткие данные: ожидалась ErrAuthentication, получено %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package customlib
+
+import (
+	"errors"
+	"io"
+)
+
+func (ctx *CryptoContext) ivSize() int {
+	return ivSizeFor(ctx.mode, ctx.blockSize)
+}
+
+func (ctx *CryptoContext) withIV(iv []byte) *CryptoContext {
+	msgCtx := *ctx
+	msgCtx.iv = iv
+	msgCtx.autoIV = false
+	return &msgCtx
+}
+
+func (ctx *CryptoContext) freshIV() ([]byte, error) {
+	iv := make([]byte, ctx.ivSize())
+	_, err := io.ReadFull(ctx.randomSource(), iv)
+	if err != nil {
+		return nil, err
+	}
+	return iv, nil
+}
+
+func (ctx *CryptoContext) sealWithFreshIV(data []byte, encrypt func(*CryptoContext, []byte) ([]byte, error)) ([]byte, error) {
+	iv, err := ctx.freshIV()
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := encrypt(ctx.withIV(iv), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(iv, ciphertext...), nil
+}
+
+func (ctx *CryptoContext) openWithPrefixedIV(data []byte, decrypt func(*CryptoContext, []byte) ([]byte, error)) ([]byte, error) {
+	ivSize := ctx.ivSize()
+	if len(data) < ivSize {
+		return nil, errors.New("данные короче вектора инициализации")
+	}
+
+	return decrypt(ctx.withIV(cloneBytes(data[:ivSize])), data[ivSize:])
+}
@@ -0,0 +1,182 @@
+package customlib_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/rijndael"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("источник случайности недоступен")
+}
+
+func newPerMessageContext(t *testing.T, mode customlib.CipherMode, opts ...customlib.Option) *customlib.CryptoContext {
+	t.Helper()
+	aes, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts = append([]customlib.Option{customlib.WithMode(mode), customlib.WithIVPerMessage()}, opts...)
+	ctx, err := customlib.NewCryptoContextWithOptions(keyA, aes, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func TestPerMessageIVDiffers(t *testing.T) {
+	ctx := newPerMessageContext(t, customlib.ModeCBC)
+	plaintext := testMessage(40)
+
+	first, err := ctx.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ctx.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 16+48 {
+		t.Fatalf("длина шифртекста %d, ожидалось %d", len(first), 16+48)
+	}
+	if bytes.Equal(first[:16], second[:16]) {
+		t.Error("два сообщения получили одинаковый IV")
+	}
+	if bytes.Equal(first[16:], second[16:]) {
+		t.Error("два сообщения получили одинаковый шифртекст")
+	}
+}
+
+func TestPerMessageIVUsesRand(t *testing.T) {
+	iv := testMessage(16)
+	ctx := newPerMessageContext(t, customlib.ModeCBC, customlib.WithRand(bytes.NewReader(iv)))
+
+	ciphertext, err := ctx.Encrypt(testMessage(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ciphertext[:16], iv) {
+		t.Errorf("IV %x, ожидался %x из WithRand", ciphertext[:16], iv)
+	}
+
+	expected, err := newAESContext(t, keyA, customlib.ModeCBC, iv).Encrypt(testMessage(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ciphertext[16:], expected) {
+		t.Error("шифртекст не совпал с шифрованием на том же IV")
+	}
+
+	if _, err := ctx.Encrypt(testMessage(20)); err == nil {
+		t.Error("ожидалась ошибка исчерпанного источника случайности")
+	}
+
+	ctx = newPerMessageContext(t, customlib.ModeCTR, customlib.WithRand(failingReader{}))
+	if _, err := ctx.Encrypt(testMessage(20)); err == nil {
+		t.Error("ожидалась ошибка источника случайности")
+	}
+	if _, err := customlib.NewEncryptWriter(io.Discard, ctx); err == nil {
+		t.Error("ожидалась ошибка источника случайности в потоке")
+	}
+}
+
+func TestPerMessageIVRoundTrip(t *testing.T) {
+	plaintext := testMessage(5000)
+
+	for _, mode := range []customlib.CipherMode{customlib.ModeCBC, customlib.ModeCFB, customlib.ModeOFB, customlib.ModeCTR} {
+		ctx := newPerMessageContext(t, mode)
+
+		ciphertext, err := ctx.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decrypted, err := ctx.Decrypt(ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("режим %d: Decrypt не совпал", mode)
+		}
+
+		var buf bytes.Buffer
+		w, err := customlib.NewEncryptWriter(&buf, ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(plaintext); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := customlib.NewDecryptReader(bytes.NewReader(buf.Bytes()), ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		streamed, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(streamed, plaintext) {
+			t.Errorf("режим %d: поток не совпал", mode)
+		}
+
+		decrypted, err = ctx.Decrypt(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("режим %d: Decrypt потока не совпал", mode)
+		}
+	}
+}
+
+func TestPerMessageIVSeekable(t *testing.T) {
+	plaintext := testMessage(5000)
+
+	for _, mode := range []customlib.CipherMode{customlib.ModeCTR, customlib.ModeOFB} {
+		ctx := newPerMessageContext(t, mode)
+		ciphertext, err := ctx.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sr, err := customlib.NewSeekableReader(bytes.NewReader(ciphertext), int64(len(ciphertext)), ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sr.Size() != int64(len(plaintext)) {
+			t.Fatalf("режим %d: размер %d, ожидался %d", mode, sr.Size(), len(plaintext))
+		}
+
+		part := make([]byte, 300)
+		if _, err := sr.ReadAt(part, 1234); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(part, plaintext[1234:1534]) {
+			t.Errorf("режим %d: ReadAt не совпал", mode)
+		}
+	}
+}
+
+func TestPerMessageIVRejectsShortInput(t *testing.T) {
+	ctx := newPerMessageContext(t, customlib.ModeCTR)
+	short := testMessage(15)
+
+	if _, err := ctx.Decrypt(short); err == nil {
+		t.Error("Decrypt: ожидалась ошибка")
+	}
+	if _, err := customlib.NewDecryptReader(bytes.NewReader(short), ctx); err == nil {
+		t.Error("NewDecryptReader: ожидалась ошибка")
+	}
+	if _, err := customlib.NewSeekableReader(bytes.NewReader(short), int64(len(short)), ctx); err == nil {
+		t.Error("NewSeekableReader: ожидалась ошибка")
+	}
+}
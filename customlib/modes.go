@@ -0,0 +1,446 @@
+package customlib
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+)
+
+type blockMode interface {
+	cryptBlocks(data []byte) ([]byte, error)
+}
+
+func (ctx *CryptoContext) newBlockMode(decrypt bool) (blockMode, error) {
+	switch ctx.mode {
+	case ModeECB:
+		return &ecbMode{ctx: ctx, decrypt: decrypt}, nil
+	case ModeRandomDelta:
+		return &randomDeltaMode{ctx: ctx, decrypt: decrypt}, nil
+	case ModeCBC, ModePCBC, ModeCFB, ModeOFB, ModeCTR:
+	default:
+		return nil, errors.New("неподдерживаемый режим шифрования")
+	}
+
+	if err := ctx.checkIV(); err != nil {
+		return nil, err
+	}
+
+	switch ctx.mode {
+	case ModeCBC:
+		return &cbcMode{ctx: ctx, decrypt: decrypt, prev: cloneBytes(ctx.iv)}, nil
+	case ModePCBC:
+		return &pcbcMode{ctx: ctx, decrypt: decrypt, prevPlain: cloneBytes(ctx.iv), prevCipher: make([]byte, ctx.blockSize)}, nil
+	case ModeCFB:
+		return &cfbMode{ctx: ctx, decrypt: decrypt, register: cloneBytes(ctx.iv)}, nil
+	case ModeOFB:
+		return &ofbMode{ctx: ctx, stream: cloneBytes(ctx.iv)}, nil
+	default:
+		return &ctrMode{ctx: ctx, counter: cloneBytes(ctx.iv), layout: ctx.ctrLayout}, nil
+	}
+}
+
+type ecbMode struct {
+	ctx        *CryptoContext
+	decrypt    bool
+	sequential bool
+}
+
+func (m *ecbMode) cryptBlocks(data []byte) ([]byte, error) {
+	blockSize := m.ctx.blockSize
+	if len(data)%blockSize != 0 {
+		return nil, errors.New("данные не кратны размеру блока")
+	}
+
+	result := make([]byte, len(data))
+
+	if m.sequential {
+		for i := 0; i < len(data); i += blockSize {
+			outputBlock, err := m.cryptBlock(data[i : i+blockSize])
+			if err != nil {
+				return nil, err
+			}
+			copy(result[i:i+blockSize], outputBlock)
+		}
+		return result, nil
+	}
+
+	err := m.ctx.parallelBlocks(len(data)/blockSize, func(index int) error {
+		start := index * blockSize
+		outputBlock, err := m.cryptBlock(data[start : start+blockSize])
+		if err != nil {
+			return err
+		}
+
+		copy(result[start:start+blockSize], outputBlock)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (m *ecbMode) cryptBlock(block []byte) ([]byte, error) {
+	if m.decrypt {
+		return m.ctx.decryptBlock(block)
+	}
+	return m.ctx.encryptBlock(block)
+}
+
+type cbcMode struct {
+	ctx     *CryptoContext
+	decrypt bool
+	prev    []byte
+}
+
+func (m *cbcMode) cryptBlocks(data []byte) ([]byte, error) {
+	blockSize := m.ctx.blockSize
+	if len(data)%blockSize != 0 {
+		return nil, errors.New("данные не кратны размеру блока")
+	}
+
+	result := make([]byte, len(data))
+
+	for i := 0; i < len(data); i += blockSize {
+		block := data[i : i+blockSize]
+
+		if m.decrypt {
+			decryptedBlock, err := m.ctx.decryptBlock(block)
+			if err != nil {
+				return nil, err
+			}
+
+			copy(result[i:i+blockSize], xorBlocks(decryptedBlock, m.prev))
+			m.prev = cloneBytes(block)
+			continue
+		}
+
+		encryptedBlock, err := m.ctx.encryptBlock(xorBlocks(block, m.prev))
+		if err != nil {
+			return nil, err
+		}
+
+		copy(result[i:i+blockSize], encryptedBlock)
+		m.prev = encryptedBlock
+	}
+
+	return result, nil
+}
+
+type pcbcMode struct {
+	ctx        *CryptoContext
+	decrypt    bool
+	prevPlain  []byte
+	prevCipher []byte
+}
+
+func (m *pcbcMode) cryptBlocks(data []byte) ([]byte, error) {
+	blockSize := m.ctx.blockSize
+	if len(data)%blockSize != 0 {
+		return nil, errors.New("данные не кратны размеру блока")
+	}
+
+	result := make([]byte, len(data))
+
+	for i := 0; i < len(data); i += blockSize {
+		chain := xorBlocks(m.prevPlain, m.prevCipher)
+
+		if m.decrypt {
+			cipherBlock := cloneBytes(data[i : i+blockSize])
+
+			decryptedBlock, err := m.ctx.decryptBlock(cipherBlock)
+			if err != nil {
+				return nil, err
+			}
+
+			plainBlock := xorBlocks(decryptedBlock, chain)
+			copy(result[i:i+blockSize], plainBlock)
+
+			m.prevPlain = plainBlock
+			m.prevCipher = cipherBlock
+			continue
+		}
+
+		plainBlock := cloneBytes(data[i : i+blockSize])
+
+		encryptedBlock, err := m.ctx.encryptBlock(xorBlocks(plainBlock, chain))
+		if err != nil {
+			return nil, err
+		}
+
+		copy(result[i:i+blockSize], encryptedBlock)
+
+		m.prevPlain = plainBlock
+		m.prevCipher = encryptedBlock
+	}
+
+	return result, nil
+}
+
+type cfbMode struct {
+	ctx      *CryptoContext
+	decrypt  bool
+	register []byte
+}
+
+func (m *cfbMode) cryptBlocks(data []byte) ([]byte, error) {
+	if !m.ctx.fullBlockSegments() {
+		result, register, err := m.ctx.cryptSegments(data, m.register, m.decrypt, false)
+		if err != nil {
+			return nil, err
+		}
+		m.register = register
+		return result, nil
+	}
+
+	blockSize := m.ctx.blockSize
+	result := make([]byte, len(data))
+
+	for i := 0; i < len(data); i += blockSize {
+		encryptedStream, err := m.ctx.encryptBlock(m.register)
+		if err != nil {
+			return nil, err
+		}
+
+		blockSizeToUse := min(blockSize, len(data)-i)
+		outputBlock := xorBlocks(data[i:i+blockSizeToUse], encryptedStream[:blockSizeToUse])
+
+		copy(result[i:i+blockSizeToUse], outputBlock)
+
+		cipherBlock := outputBlock
+		if m.decrypt {
+			cipherBlock = data[i : i+blockSizeToUse]
+		}
+
+		register := make([]byte, blockSize)
+		copy(register, m.register[blockSizeToUse:])
+		copy(register[blockSize-blockSizeToUse:], cipherBlock)
+		m.register = register
+	}
+
+	return result, nil
+}
+
+type ofbMode struct {
+	ctx    *CryptoContext
+	stream []byte
+}
+
+func (m *ofbMode) cryptBlocks(data []byte) ([]byte, error) {
+	if !m.ctx.fullBlockSegments() {
+		result, stream, err := m.ctx.cryptSegments(data, m.stream, false, true)
+		if err != nil {
+			return nil, err
+		}
+		m.stream = stream
+		return result, nil
+	}
+
+	blockSize := m.ctx.blockSize
+	result := make([]byte, len(data))
+
+	for i := 0; i < len(data); i += blockSize {
+		encryptedStream, err := m.ctx.encryptBlock(m.stream)
+		if err != nil {
+			return nil, err
+		}
+
+		blockSizeToUse := min(blockSize, len(data)-i)
+		outputBlock := xorBlocks(data[i:i+blockSizeToUse], encryptedStream[:blockSizeToUse])
+
+		copy(result[i:i+blockSizeToUse], outputBlock)
+
+		m.stream = encryptedStream
+	}
+
+	return result, nil
+}
+
+type ctrMode struct {
+	ctx     *CryptoContext
+	counter []byte
+	layout  CTRLayout
+	started bool
+}
+
+func (m *ctrMode) cryptBlocks(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if m.started {
+		err := m.layout.add(m.counter, 1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	m.started = true
+
+	return m.ctx.xorKeyStream(data, m.counter, m.layout)
+}
+
+func (ctx *CryptoContext) xorKeyStream(data []byte, counter []byte, layout CTRLayout) ([]byte, error) {
+	blockSize := ctx.blockSize
+	result := make([]byte, len(data))
+
+	for i := 0; i < len(data); i += blockSize {
+		if i > 0 {
+			err := layout.add(counter, 1)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		encryptedCounter, err := ctx.encryptBlock(counter)
+		if err != nil {
+			return nil, err
+		}
+
+		blockSizeToUse := min(blockSize, len(data)-i)
+		outputBlock := xorBlocks(data[i:i+blockSizeToUse], encryptedCounter[:blockSizeToUse])
+
+		copy(result[i:i+blockSizeToUse], outputBlock)
+	}
+
+	return result, nil
+}
+
+type randomDeltaMode struct {
+	ctx     *CryptoContext
+	decrypt bool
+	seed    []byte
+	index   int
+}
+
+func (m *randomDeltaMode) cryptBlocks(data []byte) ([]byte, error) {
+	blockSize := m.ctx.blockSize
+	if len(data)%blockSize != 0 {
+		return nil, errors.New("данные не кратны размеру блока")
+	}
+
+	var header []byte
+	if m.seed == nil {
+		if m.decrypt {
+			if len(data) == 0 {
+				return nil, nil
+			}
+
+			seed, err := m.ctx.decryptBlock(data[:blockSize])
+			if err != nil {
+				return nil, err
+			}
+
+			m.seed = seed
+			data = data[blockSize:]
+		} else {
+			seed := make([]byte, blockSize)
+			_, err := io.ReadFull(m.ctx.randomSource(), seed)
+			if err != nil {
+				return nil, err
+			}
+
+			header, err = m.ctx.encryptBlock(seed)
+			if err != nil {
+				return nil, err
+			}
+
+			m.seed = seed
+		}
+	}
+
+	result := make([]byte, len(header)+len(data))
+	copy(result, header)
+
+	processBlock := func(block, delta []byte) ([]byte, error) {
+		return m.ctx.encryptBlock(xorBlocks(block, delta))
+	}
+	if m.decrypt {
+		processBlock = func(block, delta []byte) ([]byte, error) {
+			decryptedBlock, err := m.ctx.decryptBlock(block)
+			if err != nil {
+				return nil, err
+			}
+			return xorBlocks(decryptedBlock, delta), nil
+		}
+	}
+
+	err := m.ctx.processRandomDelta(m.seed, m.index, data, result[len(header):], processBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	m.index += len(data) / blockSize
+	return result, nil
+}
+
+func (ctx *CryptoContext) processRandomDelta(seed []byte, firstIndex int, data []byte, result []byte, processBlock func(block, delta []byte) ([]byte, error)) error {
+	blockSize := ctx.blockSize
+
+	return ctx.parallelBlocks(len(data)/blockSize, func(index int) error {
+		start := index * blockSize
+		delta := randomDelta(seed, firstIndex+index)
+
+		outputBlock, err := processBlock(data[start:start+blockSize], delta)
+		if err != nil {
+			return err
+		}
+
+		copy(result[start:start+blockSize], outputBlock)
+		return nil
+	})
+}
+
+func (ctx *CryptoContext) parallelBlocks(count int, process func(index int) error) error {
+	workers := min(ctx.workerCount(), count)
+	if workers <= 1 {
+		for i := 0; i < count; i++ {
+			if err := process(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	var firstErr error
+	var errOnce sync.Once
+
+	perWorker := (count + workers - 1) / workers
+	for first := 0; first < count; first += perWorker {
+		wg.Add(1)
+		go func(first, last int) {
+			defer wg.Done()
+
+			for i := first; i < last; i++ {
+				if err := process(i); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+					})
+					return
+				}
+			}
+		}(first, min(first+perWorker, count))
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+func randomDelta(seed []byte, index int) []byte {
+	modulus := new(big.Int).Lsh(big.NewInt(1), uint(len(seed)*8))
+	step := new(big.Int).SetBytes(seed[len(seed)/2:])
+
+	delta := new(big.Int).Mul(step, big.NewInt(int64(index)+1))
+	delta.Add(delta, new(big.Int).SetBytes(seed))
+	delta.Mod(delta, modulus)
+
+	return delta.FillBytes(make([]byte, len(seed)))
+}
+
+func cloneBytes(data []byte) []byte {
+	result := make([]byte, len(data))
+	copy(result, data)
+	return result
+}
@@ -0,0 +1,162 @@
+package customlib_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/des"
+	"iSL1/rijndael"
+)
+
+var allPaddings = []struct {
+	name    string
+	padding customlib.PaddingMode
+}{
+	{"zeros", customlib.PaddingZeros},
+	{"ansix923", customlib.PaddingANSIX923},
+	{"pkcs7", customlib.PaddingPKCS7},
+	{"iso10126", customlib.PaddingISO10126},
+	{"iso7816", customlib.PaddingISO7816},
+}
+
+func testCiphers(t *testing.T) map[string]func() customlib.BlockCipher {
+	return map[string]func() customlib.BlockCipher{
+		"des": func() customlib.BlockCipher {
+			c, err := des.NewDES()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return c
+		},
+		"aes": func() customlib.BlockCipher {
+			c, err := rijndael.NewAES()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return c
+		},
+	}
+}
+
+func testMessage(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i*7 + 1)
+	}
+	return data
+}
+
+func TestRandomDeltaRoundTrip(t *testing.T) {
+	for cipherName, newCipher := range testCiphers(t) {
+		for _, p := range allPaddings {
+			t.Run(cipherName+"/"+p.name, func(t *testing.T) {
+				cipher := newCipher()
+				ctx, err := customlib.NewCryptoContextWithCipher(testMessage(cipher.BlockSize()), customlib.ModeRandomDelta, p.padding, nil, cipher)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				for n := 0; n <= 3*cipher.BlockSize()+1; n++ {
+					plaintext := testMessage(n)
+					ciphertext, err := ctx.Encrypt(plaintext)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					decrypted, err := ctx.Decrypt(ciphertext)
+					if err != nil {
+						t.Fatal(fmt.Errorf("длина %d: %w", n, err))
+					}
+					if !bytes.Equal(decrypted, plaintext) {
+						t.Errorf("длина %d: расшифровано %x, ожидалось %x", n, decrypted, plaintext)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRandomDeltaFreshSeed(t *testing.T) {
+	cipher, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := customlib.NewCryptoContextWithCipher(testMessage(16), customlib.ModeRandomDelta, customlib.PaddingPKCS7, nil, cipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := testMessage(48)
+	first, err := ctx.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ctx.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first, second) {
+		t.Error("повторное шифрование дало тот же шифртекст")
+	}
+}
+
+func TestPCBCChaining(t *testing.T) {
+	key := testMessage(16)
+	iv := bytes.Repeat([]byte{0x5A}, 16)
+	plaintext := testMessage(32)
+
+	newContext := func(mode customlib.CipherMode, iv []byte) *customlib.CryptoContext {
+		cipher, err := rijndael.NewAES()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, err := customlib.NewCryptoContextWithCipher(key, mode, customlib.PaddingPKCS7, iv, cipher)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ctx
+	}
+
+	ciphertext, err := newContext(customlib.ModePCBC, iv).Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cbc, err := newContext(customlib.ModeCBC, iv).Encrypt(plaintext[:16])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ciphertext[:16], cbc[:16]) {
+		t.Errorf("первый блок %x, ожидался E(P1^IV) = %x", ciphertext[:16], cbc[:16])
+	}
+
+	chain := make([]byte, 16)
+	for i := range chain {
+		chain[i] = plaintext[i] ^ ciphertext[i]
+	}
+	second, err := newContext(customlib.ModeCBC, chain).Encrypt(plaintext[16:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ciphertext[16:32], second[:16]) {
+		t.Errorf("второй блок %x, ожидался E(P2^P1^C1) = %x", ciphertext[16:32], second[:16])
+	}
+
+	other, err := newContext(customlib.ModePCBC, make([]byte, 16)).Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(other, ciphertext) {
+		t.Error("шифртекст не зависит от IV")
+	}
+
+	decrypted, err := newContext(customlib.ModePCBC, iv).Decrypt(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("расшифровано %x, ожидалось %x", decrypted, plaintext)
+	}
+}
@@ -0,0 +1,211 @@
+package customlib
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"runtime"
+)
+
+type cryptoOptions struct {
+	mode        CipherMode
+	padding     PaddingMode
+	iv          []byte
+	randomIV    bool
+	perMessage  bool
+	ctrLayout   *CTRLayout
+	segmentBits int
+	workers     int
+	random      io.Reader
+	fileMAC     MACID
+}
+
+type Option func(*cryptoOptions) error
+
+func WithMode(mode CipherMode) Option {
+	return func(o *cryptoOptions) error {
+		o.mode = mode
+		return nil
+	}
+}
+
+func WithPadding(padding PaddingMode) Option {
+	return func(o *cryptoOptions) error {
+		if _, err := LookupPadder(padding); err != nil {
+			return err
+		}
+		o.padding = padding
+		return nil
+	}
+}
+
+func WithIV(iv []byte) Option {
+	return func(o *cryptoOptions) error {
+		if iv == nil {
+			return errors.New("вектор инициализации не может быть nil")
+		}
+		o.iv = cloneBytes(iv)
+		return nil
+	}
+}
+
+func WithRandomIV() Option {
+	return func(o *cryptoOptions) error {
+		o.randomIV = true
+		return nil
+	}
+}
+
+func WithIVPerMessage() Option {
+	return func(o *cryptoOptions) error {
+		o.perMessage = true
+		return nil
+	}
+}
+
+func WithCTRLayout(layout CTRLayout) Option {
+	return func(o *cryptoOptions) error {
+		o.ctrLayout = &layout
+		return nil
+	}
+}
+
+func WithSegmentSize(bits int) Option {
+	return func(o *cryptoOptions) error {
+		if bits <= 0 {
+			return errors.New("размер сегмента должен быть положительным")
+		}
+		o.segmentBits = bits
+		return nil
+	}
+}
+
+func WithWorkers(n int) Option {
+	return func(o *cryptoOptions) error {
+		if n <= 0 {
+			return errors.New("число потоков должно быть положительным")
+		}
+		o.workers = n
+		return nil
+	}
+}
+
+func WithRand(r io.Reader) Option {
+	return func(o *cryptoOptions) error {
+		if r == nil {
+			return errors.New("источник случайности не может быть nil")
+		}
+		o.random = r
+		return nil
+	}
+}
+
+func WithFileMAC(id MACID) Option {
+	return func(o *cryptoOptions) error {
+		if err := checkFileMAC(id); err != nil {
+			return err
+		}
+		o.fileMAC = id
+		return nil
+	}
+}
+
+func NewCryptoContextWithOptions(key []byte, cipher BlockCipher, opts ...Option) (*CryptoContext, error) {
+	o := &cryptoOptions{mode: ModeCBC, padding: PaddingPKCS7, random: rand.Reader}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+
+	switch o.mode {
+	case ModeECB, ModeCBC, ModePCBC, ModeCFB, ModeOFB, ModeCTR, ModeRandomDelta, ModeGCM, ModeCBCCS1, ModeCBCCS2, ModeCBCCS3:
+	case ModeXTS:
+		return nil, errors.New("для режима XTS используйте NewXTSCryptoContext")
+	default:
+		return nil, errors.New("неподдерживаемый режим шифрования")
+	}
+
+	if o.iv != nil && o.randomIV {
+		return nil, errors.New("WithIV и WithRandomIV нельзя использовать одновременно")
+	}
+	if o.perMessage && (o.iv != nil || o.randomIV) {
+		return nil, errors.New("WithIVPerMessage нельзя использовать вместе с WithIV или WithRandomIV")
+	}
+	if o.ctrLayout != nil && o.mode != ModeCTR {
+		return nil, errors.New("раскладка счётчика применима только в режиме CTR")
+	}
+	if o.segmentBits != 0 && o.mode != ModeCFB && o.mode != ModeOFB {
+		return nil, errors.New("размер сегмента применим только в режимах CFB и OFB")
+	}
+
+	ctx, err := NewCryptoContextWithCipher(key, o.mode, o.padding, nil, cipher)
+	if err != nil {
+		return nil, err
+	}
+	ctx.workers = o.workers
+	ctx.random = o.random
+	ctx.fileMAC = o.fileMAC
+
+	if o.mode == ModeGCM && ctx.blockSize != gcmBlockSize {
+		return nil, errors.New("режим GCM требует блочный шифр с размером блока 128 бит")
+	}
+
+	ivSize := ivSizeFor(o.mode, ctx.blockSize)
+	switch {
+	case ivSize == 0 && (o.iv != nil || o.randomIV || o.perMessage):
+		return nil, errors.New("режим шифрования не использует вектор инициализации")
+	case ivSize == 0:
+	case o.perMessage:
+		ctx.autoIV = true
+	case o.randomIV:
+		ctx.iv = make([]byte, ivSize)
+		if _, err := io.ReadFull(o.random, ctx.iv); err != nil {
+			return nil, err
+		}
+	case o.iv == nil:
+		return nil, errors.New("режим шифрования требует вектор инициализации: укажите WithIV или WithRandomIV")
+	case o.mode == ModeGCM && len(o.iv) == 0, o.mode != ModeGCM && len(o.iv) != ivSize:
+		return nil, errors.New("длина вектора инициализации не совпадает с размером блока")
+	default:
+		ctx.iv = o.iv
+	}
+
+	if o.ctrLayout != nil {
+		if err := ctx.SetCTRLayout(*o.ctrLayout); err != nil {
+			return nil, err
+		}
+	}
+	if o.segmentBits != 0 {
+		if err := ctx.SetSegmentSize(o.segmentBits); err != nil {
+			return nil, err
+		}
+	}
+
+	return ctx, nil
+}
+
+func ivSizeFor(mode CipherMode, blockSize int) int {
+	switch mode {
+	case ModeECB, ModeRandomDelta, ModeXTS:
+		return 0
+	case ModeGCM:
+		return 12
+	default:
+		return blockSize
+	}
+}
+
+func (ctx *CryptoContext) workerCount() int {
+	if ctx.workers > 0 {
+		return ctx.workers
+	}
+	return runtime.NumCPU()
+}
+
+func (ctx *CryptoContext) randomSource() io.Reader {
+	if ctx.random != nil {
+		return ctx.random
+	}
+	return rand.Reader
+}
@@ -0,0 +1,74 @@
+package customlib_test
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"iSL1/customlib"
+	"iSL1/rijndael"
+)
+
+type concurrencyProbe struct {
+	customlib.BlockCipher
+	active atomic.Int32
+	peak   atomic.Int32
+}
+
+func (p *concurrencyProbe) track() func() {
+	n := p.active.Add(1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(50 * time.Microsecond)
+	return func() { p.active.Add(-1) }
+}
+
+func (p *concurrencyProbe) EncryptBlock(block []byte) ([]byte, error) {
+	defer p.track()()
+	return p.BlockCipher.EncryptBlock(block)
+}
+
+func (p *concurrencyProbe) DecryptBlock(block []byte) ([]byte, error) {
+	defer p.track()()
+	return p.BlockCipher.DecryptBlock(block)
+}
+
+func TestWithWorkersBoundsInMemoryModes(t *testing.T) {
+	plaintext := testMessage(16 * 64)
+
+	for _, mode := range []customlib.CipherMode{customlib.ModeECB, customlib.ModeRandomDelta} {
+		for _, workers := range []int{1, 3} {
+			aes, err := rijndael.NewAES()
+			if err != nil {
+				t.Fatal(err)
+			}
+			probe := &concurrencyProbe{BlockCipher: aes}
+
+			ctx, err := customlib.NewCryptoContextWithOptions(testMessage(16), probe, customlib.WithMode(mode), customlib.WithWorkers(workers))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ciphertext, err := ctx.Encrypt(plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decrypted, err := ctx.Decrypt(ciphertext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("режим %d, потоков %d: расшифрованные данные не совпадают", mode, workers)
+			}
+
+			if peak := probe.peak.Load(); peak > int32(workers) {
+				t.Errorf("режим %d: одновременно %d блоков при ограничении %d", mode, peak, workers)
+			}
+		}
+	}
+}
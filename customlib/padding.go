@@ -0,0 +1,203 @@
+package customlib
+
+import (
+	"crypto/rand"
+	"errors"
+	"sync"
+)
+
+type Padder interface {
+	Pad(data []byte, blockSize int) ([]byte, error)
+	Unpad(data []byte, blockSize int) ([]byte, error)
+}
+
+var (
+	paddersMu sync.RWMutex
+	padders   = map[PaddingMode]Padder{
+		PaddingZeros:    ZerosPadder{},
+		PaddingANSIX923: ANSIX923Padder{},
+		PaddingPKCS7:    PKCS7Padder{},
+		PaddingISO10126: ISO10126Padder{},
+		PaddingISO7816:  ISO7816Padder{},
+	}
+)
+
+func RegisterPadder(mode PaddingMode, padder Padder) error {
+	if padder == nil {
+		return errors.New("Padder не может быть nil")
+	}
+
+	paddersMu.Lock()
+	defer paddersMu.Unlock()
+
+	if _, ok := padders[mode]; ok {
+		return errors.New("режим набивки уже зарегистрирован")
+	}
+	padders[mode] = padder
+	return nil
+}
+
+func LookupPadder(mode PaddingMode) (Padder, error) {
+	paddersMu.RLock()
+	defer paddersMu.RUnlock()
+
+	padder, ok := padders[mode]
+	if !ok {
+		return nil, errors.New("неподдерживаемый режим набивки")
+	}
+	return padder, nil
+}
+
+type ZerosPadder struct{}
+
+func (ZerosPadder) Pad(data []byte, blockSize int) ([]byte, error) {
+	return padWith(data, blockSize, func(padding []byte) error {
+		return nil
+	})
+}
+
+func (ZerosPadder) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkPadded(data, blockSize); err != nil {
+		return nil, err
+	}
+
+	end := len(data)
+	for end > 0 && data[end-1] == 0 {
+		end--
+	}
+	return data[:end], nil
+}
+
+type ANSIX923Padder struct{}
+
+func (ANSIX923Padder) Pad(data []byte, blockSize int) ([]byte, error) {
+	return padWith(data, blockSize, func(padding []byte) error {
+		padding[len(padding)-1] = byte(len(padding))
+		return nil
+	})
+}
+
+func (ANSIX923Padder) Unpad(data []byte, blockSize int) ([]byte, error) {
+	paddingLength, err := paddingLength(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range data[len(data)-paddingLength : len(data)-1] {
+		if b != 0 {
+			return nil, errors.New("неверные данные набивки")
+		}
+	}
+	return data[:len(data)-paddingLength], nil
+}
+
+type PKCS7Padder struct{}
+
+func (PKCS7Padder) Pad(data []byte, blockSize int) ([]byte, error) {
+	return padWith(data, blockSize, func(padding []byte) error {
+		for i := range padding {
+			padding[i] = byte(len(padding))
+		}
+		return nil
+	})
+}
+
+func (PKCS7Padder) Unpad(data []byte, blockSize int) ([]byte, error) {
+	paddingLength, err := paddingLength(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range data[len(data)-paddingLength:] {
+		if b != byte(paddingLength) {
+			return nil, errors.New("неверные данные набивки")
+		}
+	}
+	return data[:len(data)-paddingLength], nil
+}
+
+type ISO10126Padder struct{}
+
+func (ISO10126Padder) Pad(data []byte, blockSize int) ([]byte, error) {
+	return padWith(data, blockSize, func(padding []byte) error {
+		_, err := rand.Read(padding[:len(padding)-1])
+		if err != nil {
+			return err
+		}
+		padding[len(padding)-1] = byte(len(padding))
+		return nil
+	})
+}
+
+func (ISO10126Padder) Unpad(data []byte, blockSize int) ([]byte, error) {
+	paddingLength, err := paddingLength(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	return data[:len(data)-paddingLength], nil
+}
+
+type ISO7816Padder struct{}
+
+func (ISO7816Padder) Pad(data []byte, blockSize int) ([]byte, error) {
+	return padWith(data, blockSize, func(padding []byte) error {
+		padding[0] = 0x80
+		return nil
+	})
+}
+
+func (ISO7816Padder) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkPadded(data, blockSize); err != nil {
+		return nil, err
+	}
+
+	for i := len(data) - 1; i >= 0 && i >= len(data)-blockSize; i-- {
+		switch data[i] {
+		case 0x80:
+			return data[:i], nil
+		case 0:
+		default:
+			return nil, errors.New("неверные данные набивки")
+		}
+	}
+	return nil, errors.New("неверные данные набивки")
+}
+
+func padWith(data []byte, blockSize int, fill func(padding []byte) error) ([]byte, error) {
+	if blockSize <= 0 || blockSize > 255 {
+		return nil, errors.New("неподдерживаемый размер блока")
+	}
+
+	paddingNeeded := blockSize - len(data)%blockSize
+	result := make([]byte, len(data)+paddingNeeded)
+	copy(result, data)
+
+	err := fill(result[len(data):])
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func checkPadded(data []byte, blockSize int) error {
+	if blockSize <= 0 || blockSize > 255 {
+		return errors.New("неподдерживаемый размер блока")
+	}
+	if len(data) == 0 {
+		return errors.New("данные пусты")
+	}
+	if len(data)%blockSize != 0 {
+		return errors.New("данные не кратны размеру блока")
+	}
+	return nil
+}
+
+func paddingLength(data []byte, blockSize int) (int, error) {
+	if err := checkPadded(data, blockSize); err != nil {
+		return 0, err
+	}
+
+	length := int(data[len(data)-1])
+	if length == 0 || length > blockSize {
+		return 0, errors.New("неверная длина набивки")
+	}
+	return length, nil
+}
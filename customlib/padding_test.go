@@ -0,0 +1,174 @@
+package customlib_test
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/rijndael"
+)
+
+func TestPadders(t *testing.T) {
+	tests := []struct {
+		name   string
+		padder customlib.Padder
+		data   string
+		padded string
+	}{
+		{"zeros", customlib.ZerosPadder{}, "abcde", "abcde\x00\x00\x00"},
+		{"ansix923", customlib.ANSIX923Padder{}, "abcde", "abcde\x00\x00\x03"},
+		{"ansix923-full", customlib.ANSIX923Padder{}, "abcdefgh", "abcdefgh\x00\x00\x00\x00\x00\x00\x00\x08"},
+		{"pkcs7", customlib.PKCS7Padder{}, "abcde", "abcde\x03\x03\x03"},
+		{"pkcs7-full", customlib.PKCS7Padder{}, "abcdefgh", "abcdefgh\x08\x08\x08\x08\x08\x08\x08\x08"},
+		{"iso7816", customlib.ISO7816Padder{}, "abcde", "abcde\x80\x00\x00"},
+		{"iso7816-one", customlib.ISO7816Padder{}, "abcdefg", "abcdefg\x80"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			padded, err := tc.padder.Pad([]byte(tc.data), 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(padded, []byte(tc.padded)) {
+				t.Errorf("набивка %x, ожидалась %x", padded, tc.padded)
+			}
+
+			unpadded, err := tc.padder.Unpad(padded, 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(unpadded, []byte(tc.data)) {
+				t.Errorf("снятие набивки %q, ожидалось %q", unpadded, tc.data)
+			}
+		})
+	}
+}
+
+func TestISO10126Padder(t *testing.T) {
+	padded, err := customlib.ISO10126Padder{}.Pad([]byte("abcde"), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(padded) != 8 || padded[7] != 3 || !bytes.Equal(padded[:5], []byte("abcde")) {
+		t.Errorf("неверная набивка %x", padded)
+	}
+
+	unpadded, err := customlib.ISO10126Padder{}.Unpad(padded, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unpadded, []byte("abcde")) {
+		t.Errorf("снятие набивки %q", unpadded)
+	}
+}
+
+func TestPaddersRejectInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		padder customlib.Padder
+		data   string
+	}{
+		{"pkcs7-mismatch", customlib.PKCS7Padder{}, "abcde\x03\x02\x03"},
+		{"pkcs7-zero", customlib.PKCS7Padder{}, "abcdefg\x00"},
+		{"pkcs7-too-long", customlib.PKCS7Padder{}, "abcdefg\x09"},
+		{"ansix923-nonzero", customlib.ANSIX923Padder{}, "abcde\x01\x00\x03"},
+		{"ansix923-too-long", customlib.ANSIX923Padder{}, "abcdefg\x09"},
+		{"iso10126-zero", customlib.ISO10126Padder{}, "abcdefg\x00"},
+		{"iso7816-no-marker", customlib.ISO7816Padder{}, "abcde\x00\x00\x00"},
+		{"iso7816-garbage", customlib.ISO7816Padder{}, "abcde\x80\x00\x01"},
+		{"pkcs7-not-aligned", customlib.PKCS7Padder{}, "abcde\x01"},
+		{"zeros-empty", customlib.ZerosPadder{}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.padder.Unpad([]byte(tc.data), 8); err == nil {
+				t.Error("ожидалась ошибка")
+			}
+		})
+	}
+
+	if _, err := (customlib.PKCS7Padder{}).Pad([]byte("abc"), 256); err == nil {
+		t.Error("ожидалась ошибка для блока 256 байт")
+	}
+}
+
+const customPadding customlib.PaddingMode = 100
+
+type countingPadder struct {
+	calls atomic.Int32
+}
+
+func (p *countingPadder) Pad(data []byte, blockSize int) ([]byte, error) {
+	p.calls.Add(1)
+	return customlib.ISO7816Padder{}.Pad(data, blockSize)
+}
+
+func (p *countingPadder) Unpad(data []byte, blockSize int) ([]byte, error) {
+	p.calls.Add(1)
+	return customlib.ISO7816Padder{}.Unpad(data, blockSize)
+}
+
+var registeredPadder = &countingPadder{}
+
+func registerCustomPadder(t *testing.T) {
+	t.Helper()
+	if _, err := customlib.LookupPadder(customPadding); err == nil {
+		return
+	}
+	if err := customlib.RegisterPadder(customPadding, registeredPadder); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegisterPadder(t *testing.T) {
+	if err := customlib.RegisterPadder(customlib.PaddingPKCS7, customlib.ZerosPadder{}); err == nil {
+		t.Error("ожидалась ошибка повторной регистрации")
+	}
+	if err := customlib.RegisterPadder(customPadding+1, nil); err == nil {
+		t.Error("ожидалась ошибка для nil")
+	}
+	if _, err := customlib.LookupPadder(customPadding + 1); err == nil {
+		t.Error("nil не должен регистрироваться")
+	}
+
+	registerCustomPadder(t)
+	if err := customlib.RegisterPadder(customPadding, registeredPadder); err == nil {
+		t.Error("ожидалась ошибка повторной регистрации")
+	}
+}
+
+func TestCustomPadderThroughContext(t *testing.T) {
+	registerCustomPadder(t)
+	before := registeredPadder.calls.Load()
+
+	aes, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := customlib.NewCryptoContextWithCipher(keyA, customlib.ModeCBC, customPadding, make([]byte, 16), aes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := testMessage(37)
+	ciphertext, err := ctx.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ciphertext) != 48 {
+		t.Errorf("длина шифртекста %d, ожидалось 48", len(ciphertext))
+	}
+	decrypted, err := ctx.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Error("расшифровка не совпала")
+	}
+	if calls := registeredPadder.calls.Load() - before; calls != 2 {
+		t.Errorf("зарегистрированный Padder вызван %d раз, ожидалось 2", calls)
+	}
+}
@@ -0,0 +1,226 @@
+package customlib
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"iSL1/kdf"
+)
+
+const (
+	maxPBKDF2Iterations = 10000000
+	maxScryptLogN       = 30
+	maxScryptMemory     = 256 << 20
+	maxScryptP          = 64
+)
+
+type PasswordOptions struct {
+	KDF        KDFID
+	KeySize    int
+	SaltSize   int
+	Iterations int
+	ScryptN    int
+	ScryptR    int
+	ScryptP    int
+	MAC        MACID
+}
+
+func NewCryptoContextFromPassword(password []byte, cipher BlockCipher, mode CipherMode, padding PaddingMode, opts PasswordOptions) (*CryptoContext, error) {
+	if cipher == nil {
+		return nil, errors.New("блочный шифр не может быть nil")
+	}
+	if opts.KeySize <= 0 {
+		return nil, errors.New("размер ключа должен быть положительным")
+	}
+	if err := checkFileMAC(opts.MAC); err != nil {
+		return nil, err
+	}
+
+	params, err := newKDFParams(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ivSize := ivSizeFor(mode, cipher.BlockSize())
+
+	material, err := DeriveKey(password, params, opts.KeySize+ivSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var iv []byte
+	if ivSize > 0 {
+		iv = material[opts.KeySize:]
+	}
+
+	ctx, err := NewCryptoContextWithCipher(material[:opts.KeySize], mode, padding, iv, cipher)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.kdf = params
+	ctx.fileMAC = opts.MAC
+	return ctx, nil
+}
+
+func NewCryptoContextFromPasswordHeader(password []byte, cipher BlockCipher, header *FileHeader, keySize int) (*CryptoContext, error) {
+	if cipher == nil {
+		return nil, errors.New("блочный шифр не может быть nil")
+	}
+	if header == nil {
+		return nil, errors.New("заголовок файла не может быть nil")
+	}
+	if header.KDF.ID == KDFNone {
+		return nil, errors.New("файл зашифрован без пароля")
+	}
+	if keySize <= 0 {
+		return nil, errors.New("размер ключа должен быть положительным")
+	}
+
+	key, err := DeriveKey(password, header.KDF, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	var iv []byte
+	if len(header.IV) > 0 {
+		iv = header.IV
+	}
+
+	ctx, err := NewCryptoContextWithCipher(key, header.Mode, header.Padding, iv, cipher)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.kdf = header.KDF
+	ctx.fileMAC = header.MAC
+	return ctx, nil
+}
+
+func (ctx *CryptoContext) KDFParams() KDFParams {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.kdf
+}
+
+func DeriveKey(password []byte, params KDFParams, keySize int) ([]byte, error) {
+	if len(params.Params) < 1 || len(params.Params) < 1+int(params.Params[0]) {
+		return nil, errors.New("неверные параметры KDF")
+	}
+
+	saltSize := int(params.Params[0])
+	salt := params.Params[1 : 1+saltSize]
+	rest := params.Params[1+saltSize:]
+
+	switch params.ID {
+	case KDFPBKDF2SHA256, KDFPBKDF2SHA512:
+		if len(rest) != 4 {
+			return nil, errors.New("неверные параметры KDF")
+		}
+
+		iterations := int(binary.BigEndian.Uint32(rest))
+		if err := checkPBKDF2Cost(iterations); err != nil {
+			return nil, err
+		}
+
+		var h func() hash.Hash = sha256.New
+		if params.ID == KDFPBKDF2SHA512 {
+			h = sha512.New
+		}
+		return kdf.PBKDF2(password, salt, iterations, keySize, h)
+	case KDFScrypt:
+		if len(rest) != 9 {
+			return nil, errors.New("неверные параметры KDF")
+		}
+
+		logN := int(rest[0])
+		r := int(binary.BigEndian.Uint32(rest[1:5]))
+		p := int(binary.BigEndian.Uint32(rest[5:9]))
+		if err := checkScryptCost(logN, r, p); err != nil {
+			return nil, err
+		}
+		return kdf.Scrypt(password, salt, 1<<logN, r, p, keySize)
+	default:
+		return nil, errors.New("неподдерживаемый алгоритм KDF")
+	}
+}
+
+func newKDFParams(opts PasswordOptions) (KDFParams, error) {
+	if opts.KDF == KDFNone {
+		opts.KDF = KDFPBKDF2SHA256
+	}
+	if opts.SaltSize == 0 {
+		opts.SaltSize = 16
+	}
+	if opts.SaltSize < 8 || opts.SaltSize > 255 {
+		return KDFParams{}, errors.New("размер соли должен быть от 8 до 255 байт")
+	}
+
+	salt := make([]byte, opts.SaltSize)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return KDFParams{}, err
+	}
+
+	params := append([]byte{byte(opts.SaltSize)}, salt...)
+
+	switch opts.KDF {
+	case KDFPBKDF2SHA256, KDFPBKDF2SHA512:
+		if opts.Iterations == 0 {
+			opts.Iterations = 100000
+		}
+		if err := checkPBKDF2Cost(opts.Iterations); err != nil {
+			return KDFParams{}, err
+		}
+		params = binary.BigEndian.AppendUint32(params, uint32(opts.Iterations))
+	case KDFScrypt:
+		if opts.ScryptN == 0 {
+			opts.ScryptN = 1 << 15
+		}
+		if opts.ScryptR == 0 {
+			opts.ScryptR = 8
+		}
+		if opts.ScryptP == 0 {
+			opts.ScryptP = 1
+		}
+		if opts.ScryptN <= 1 || opts.ScryptN&(opts.ScryptN-1) != 0 {
+			return KDFParams{}, errors.New("параметр N должен быть степенью двойки больше 1")
+		}
+
+		logN := 0
+		for 1<<logN < opts.ScryptN {
+			logN++
+		}
+		if err := checkScryptCost(logN, opts.ScryptR, opts.ScryptP); err != nil {
+			return KDFParams{}, err
+		}
+		params = append(params, byte(logN))
+		params = binary.BigEndian.AppendUint32(params, uint32(opts.ScryptR))
+		params = binary.BigEndian.AppendUint32(params, uint32(opts.ScryptP))
+	default:
+		return KDFParams{}, errors.New("неподдерживаемый алгоритм KDF")
+	}
+
+	return KDFParams{ID: opts.KDF, Params: params}, nil
+}
+
+func checkPBKDF2Cost(iterations int) error {
+	if iterations <= 0 || iterations > maxPBKDF2Iterations {
+		return errors.New("число итераций PBKDF2 вне допустимого диапазона")
+	}
+	return nil
+}
+
+func checkScryptCost(logN, r, p int) error {
+	if logN < 1 || logN > maxScryptLogN || r <= 0 || p <= 0 || p > maxScryptP {
+		return errors.New("параметры scrypt вне допустимого диапазона")
+	}
+	if r > maxScryptMemory/128 || uint64(128*r)<<logN > maxScryptMemory || 128*r*p > maxScryptMemory {
+		return errors.New("параметры scrypt требуют слишком много памяти")
+	}
+	return nil
+}
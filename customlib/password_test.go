@@ -0,0 +1,142 @@
+package customlib_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/rijndael"
+)
+
+func kdfParams(id customlib.KDFID, tail []byte) customlib.KDFParams {
+	params := append([]byte{8}, make([]byte, 8)...)
+	return customlib.KDFParams{ID: id, Params: append(params, tail...)}
+}
+
+func scryptTail(logN byte, r, p uint32) []byte {
+	tail := []byte{logN}
+	tail = binary.BigEndian.AppendUint32(tail, r)
+	return binary.BigEndian.AppendUint32(tail, p)
+}
+
+func TestDeriveKeyRejectsExpensiveParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		params customlib.KDFParams
+	}{
+		{"pbkdf2 max iterations", kdfParams(customlib.KDFPBKDF2SHA256, binary.BigEndian.AppendUint32(nil, 0xFFFFFFFF))},
+		{"pbkdf2 zero iterations", kdfParams(customlib.KDFPBKDF2SHA512, binary.BigEndian.AppendUint32(nil, 0))},
+		{"scrypt huge n", kdfParams(customlib.KDFScrypt, scryptTail(31, 8, 1))},
+		{"scrypt 1 GiB", kdfParams(customlib.KDFScrypt, scryptTail(20, 8, 1))},
+		{"scrypt huge r", kdfParams(customlib.KDFScrypt, scryptTail(1, 0xFFFFFFFF, 1))},
+		{"scrypt huge p", kdfParams(customlib.KDFScrypt, scryptTail(4, 1, 0xFFFFFFFF))},
+		{"scrypt zero n", kdfParams(customlib.KDFScrypt, scryptTail(0, 8, 1))},
+	}
+
+	for _, tc := range tests {
+		if _, err := customlib.DeriveKey([]byte("password"), tc.params, 16); err == nil {
+			t.Errorf("%s: ожидалась ошибка", tc.name)
+		}
+	}
+}
+
+func TestDeriveKeyAcceptsDefaults(t *testing.T) {
+	tests := []customlib.KDFParams{
+		kdfParams(customlib.KDFPBKDF2SHA256, binary.BigEndian.AppendUint32(nil, 1000)),
+		kdfParams(customlib.KDFScrypt, scryptTail(10, 8, 1)),
+	}
+
+	for _, params := range tests {
+		key, err := customlib.DeriveKey([]byte("password"), params, 16)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(key) != 16 {
+			t.Errorf("длина ключа %d, ожидалась 16", len(key))
+		}
+	}
+}
+
+func TestPasswordOptionsLimits(t *testing.T) {
+	tests := []customlib.PasswordOptions{
+		{KDF: customlib.KDFPBKDF2SHA256, KeySize: 16, Iterations: 20000000},
+		{KDF: customlib.KDFScrypt, KeySize: 16, ScryptN: 1 << 20, ScryptR: 8},
+		{KDF: customlib.KDFScrypt, KeySize: 16, ScryptN: 1 << 10, ScryptP: 1000},
+	}
+
+	for _, opts := range tests {
+		cipher, err := rijndael.NewAES()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := customlib.NewCryptoContextFromPassword([]byte("password"), cipher, customlib.ModeCBC, customlib.PaddingPKCS7, opts); err == nil {
+			t.Errorf("%+v: ожидалась ошибка", opts)
+		}
+	}
+}
+
+func TestPasswordHeaderDecrypt(t *testing.T) {
+	tests := []customlib.PasswordOptions{
+		{KDF: customlib.KDFPBKDF2SHA256, KeySize: 16, Iterations: 1000},
+		{KDF: customlib.KDFPBKDF2SHA512, KeySize: 32, Iterations: 1000, MAC: customlib.MACHMACSHA256},
+		{KDF: customlib.KDFScrypt, KeySize: 16, ScryptN: 1 << 10},
+	}
+	plaintext := testMessage(3000)
+
+	for _, opts := range tests {
+		cipher, err := rijndael.NewAES()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, err := customlib.NewCryptoContextFromPassword([]byte("password"), cipher, customlib.ModeCBC, customlib.PaddingPKCS7, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sealed := encryptContainer(t, ctx, plaintext)
+
+		header, err := customlib.ReadFileHeader(bytes.NewReader(sealed))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cipher, err = rijndael.NewAES()
+		if err != nil {
+			t.Fatal(err)
+		}
+		fileCtx, err := customlib.NewCryptoContextFromPasswordHeader([]byte("password"), cipher, header, opts.KeySize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decrypted, err := decryptContainer(fileCtx, sealed)
+		if err != nil {
+			t.Fatalf("KDF %d: %v", opts.KDF, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("KDF %d: расшифрованные данные не совпадают", opts.KDF)
+		}
+
+		cipher, err = rijndael.NewAES()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wrongCtx, err := customlib.NewCryptoContextFromPasswordHeader([]byte("wrong"), cipher, header, opts.KeySize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decrypted, err := decryptContainer(wrongCtx, sealed); err == nil && bytes.Equal(decrypted, plaintext) {
+			t.Errorf("KDF %d: файл расшифрован неверным паролем", opts.KDF)
+		}
+	}
+}
+
+func TestPasswordHeaderRequiresKDF(t *testing.T) {
+	cipher, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &customlib.FileHeader{Mode: customlib.ModeCBC, BlockSize: 16, IV: make([]byte, 16)}
+	if _, err := customlib.NewCryptoContextFromPasswordHeader([]byte("password"), cipher, header, 16); err == nil {
+		t.Error("ожидалась ошибка для заголовка без KDF")
+	}
+}
@@ -1,11 +1,8 @@
 package customlib
 
 import (
-	"bufio"
-	"bytes"
-	"crypto/rand"
 	"errors"
-	"os"
+	"io"
 	"sync"
 )
 
@@ -19,6 +16,11 @@ const (
 	ModeOFB
 	ModeCTR
 	ModeRandomDelta
+	ModeGCM
+	ModeXTS
+	ModeCBCCS1
+	ModeCBCCS2
+	ModeCBCCS3
 )
 
 type PaddingMode int
@@ -28,6 +30,7 @@ const (
 	PaddingANSIX923
 	PaddingPKCS7
 	PaddingISO10126
+	PaddingISO7816
 )
 
 type KeyExpander interface {
@@ -39,6 +42,17 @@ type CipherTransformation interface {
 	DecryptBlock(inputBlock []byte, roundKey []byte) ([]byte, error)
 }
 
+type BlockCipher interface {
+	BlockSize() int
+	SetKey(key []byte) error
+	EncryptBlock(block []byte) ([]byte, error)
+	DecryptBlock(block []byte) ([]byte, error)
+}
+
+type BlockCipherFactory interface {
+	NewBlockCipher() (BlockCipher, error)
+}
+
 type SymmetricCipher interface {
 	SetKey(key []byte) error
 	Encrypt(data []byte) ([]byte, error)
@@ -47,24 +61,42 @@ type SymmetricCipher interface {
 
 type CryptoContext struct {
 	key         []byte
-	roundKeys   [][]byte
 	mode        CipherMode
 	padding     PaddingMode
 	iv          []byte
-	transform   CipherTransformation
-	expander    KeyExpander
-	extraParams map[string]interface{}
+	cipher      BlockCipher
+	blockSize   int
+	kdf         KDFParams
+	fileMAC     MACID
+	ctrLayout   CTRLayout
+	segmentBits int
+	tweakCipher BlockCipher
+	sectorSize  int
+	workers     int
+	random      io.Reader
+	autoIV      bool
+	mu          *sync.RWMutex
+}
+
+func NewCryptoContext(key []byte, mode CipherMode, padding PaddingMode, iv []byte, blockSize int, transform CipherTransformation, expander KeyExpander) (*CryptoContext, error) {
+	return NewCryptoContextWithCipher(key, mode, padding, iv, &roundCipher{
+		transform: transform,
+		expander:  expander,
+		blockSize: blockSize,
+	})
 }
 
-func NewCryptoContext(key []byte, mode CipherMode, padding PaddingMode, iv []byte, transform CipherTransformation, expander KeyExpander) (*CryptoContext, error) {
+func NewCryptoContextWithCipher(key []byte, mode CipherMode, padding PaddingMode, iv []byte, cipher BlockCipher) (*CryptoContext, error) {
+	if cipher == nil {
+		return nil, errors.New("блочный шифр не может быть nil")
+	}
+
 	ctx := &CryptoContext{
-		key:         key,
-		mode:        mode,
-		padding:     padding,
-		iv:          iv,
-		transform:   transform,
-		expander:    expander,
-		extraParams: make(map[string]interface{}),
+		mode:    mode,
+		padding: padding,
+		iv:      cloneBytes(iv),
+		cipher:  cipher,
+		mu:      new(sync.RWMutex),
 	}
 
 	err := ctx.SetKey(key)
@@ -76,544 +108,216 @@ func NewCryptoContext(key []byte, mode CipherMode, padding PaddingMode, iv []byt
 }
 
 func (ctx *CryptoContext) SetKey(key []byte) error {
-	if ctx.expander == nil {
-		return errors.New("KeyExpander не инициализирован")
-	}
-
-	roundKeys, err := ctx.expander.ExpandKey(key)
-	if err != nil {
-		return err
-	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
 
-	ctx.roundKeys = roundKeys
-	return nil
+	return ctx.setKey(key)
 }
 
-func (ctx *CryptoContext) applyPadding(data []byte) ([]byte, error) {
-	blockSize := len(ctx.roundKeys[0])
-	paddingNeeded := blockSize - (len(data) % blockSize)
-	if paddingNeeded == 0 {
-		paddingNeeded = blockSize
-	}
-
-	switch ctx.padding {
-	case PaddingZeros:
-		return applyZerosPadding(data, paddingNeeded), nil
-	case PaddingANSIX923:
-		return applyANSIX923Padding(data, paddingNeeded, blockSize)
-	case PaddingPKCS7:
-		return applyPKCS7Padding(data, paddingNeeded), nil
-	case PaddingISO10126:
-		return applyISO10126Padding(data, paddingNeeded)
-	default:
-		return nil, errors.New("неподдерживаемый режим набивки")
+func (ctx *CryptoContext) setKey(key []byte) error {
+	key = cloneBytes(key)
+	if ctx.mode == ModeXTS {
+		return ctx.setXTSKey(key)
 	}
-}
 
-func (ctx *CryptoContext) removePadding(data []byte) ([]byte, error) {
-	switch ctx.padding {
-	case PaddingZeros:
-		return removeZerosPadding(data), nil
-	case PaddingANSIX923:
-		return removeANSIX923Padding(data)
-	case PaddingPKCS7:
-		return removePKCS7Padding(data)
-	case PaddingISO10126:
-		return removeANSIX923Padding(data)
-	default:
-		return nil, errors.New("неподдерживаемый режим набивки")
+	cipher, err := ctx.keyedCipher(ctx.cipher, key)
+	if err != nil {
+		return err
 	}
-}
 
-func applyZerosPadding(data []byte, paddingNeeded int) []byte {
-	padding := bytes.Repeat([]byte{0}, paddingNeeded)
-	return append(data, padding...)
-}
-
-func removeZerosPadding(data []byte) []byte {
-	return bytes.TrimRight(data, "\x00")
-}
-
-func applyANSIX923Padding(data []byte, paddingNeeded int, blockSize int) ([]byte, error) {
-	padding := make([]byte, paddingNeeded)
-	padding[paddingNeeded-1] = byte(paddingNeeded)
-	return append(data, padding...), nil
-}
-
-func removeANSIX923Padding(data []byte) ([]byte, error) {
-	if len(data) == 0 {
-		return nil, errors.New("данные пусты")
-	}
-	paddingLength := int(data[len(data)-1])
-	if paddingLength > len(data) {
-		return nil, errors.New("неверная длина набивки")
+	blockSize := cipher.BlockSize()
+	if blockSize <= 0 || blockSize > 255 {
+		return errors.New("неподдерживаемый размер блока шифра")
 	}
-	return data[:len(data)-paddingLength], nil
-}
 
-func applyPKCS7Padding(data []byte, paddingNeeded int) []byte {
-	padding := bytes.Repeat([]byte{byte(paddingNeeded)}, paddingNeeded)
-	return append(data, padding...)
+	ctx.cipher = cipher
+	ctx.key = key
+	ctx.blockSize = blockSize
+	return nil
 }
 
-func removePKCS7Padding(data []byte) ([]byte, error) {
-	if len(data) == 0 {
-		return nil, errors.New("данные пусты")
-	}
-	paddingLength := int(data[len(data)-1])
-	if paddingLength > len(data) || paddingLength == 0 {
-		return nil, errors.New("неверная длина набивки")
-	}
-	for i := 1; i <= paddingLength; i++ {
-		if data[len(data)-i] != byte(paddingLength) {
-			return nil, errors.New("неверные данные набивки")
+func (ctx *CryptoContext) keyedCipher(current BlockCipher, key []byte) (BlockCipher, error) {
+	cipher := current
+	if factory, ok := current.(BlockCipherFactory); ok {
+		var err error
+		cipher, err = factory.NewBlockCipher()
+		if err != nil {
+			return nil, err
 		}
+	} else if ctx.key != nil {
+		return nil, errors.New("шифр не поддерживает смену ключа: необходима реализация BlockCipherFactory")
 	}
-	return data[:len(data)-paddingLength], nil
-}
 
-func applyISO10126Padding(data []byte, blockSize int) ([]byte, error) {
-	paddingNeeded := blockSize - (len(data) % blockSize)
-	if paddingNeeded == 0 {
-		paddingNeeded = blockSize
-	}
-	padding := make([]byte, paddingNeeded)
-	_, err := rand.Read(padding[:paddingNeeded-1])
+	err := cipher.SetKey(key)
 	if err != nil {
 		return nil, err
 	}
-	padding[paddingNeeded-1] = byte(paddingNeeded)
-	return append(data, padding...), nil
+	return cipher, nil
 }
 
-func (ctx *CryptoContext) Encrypt(data []byte) ([]byte, error) {
-	dataWithPadding, err := ctx.applyPadding(data)
-	if err != nil {
-		return nil, err
-	}
+func (ctx *CryptoContext) BlockSize() int {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
 
-	switch ctx.mode {
-	case ModeECB:
-		return ctx.encryptECB(dataWithPadding)
-	case ModeCBC:
-		return ctx.encryptCBC(dataWithPadding)
-	case ModePCBC:
-		return ctx.encryptPCBC(dataWithPadding)
-	case ModeCFB:
-		return ctx.encryptCFB(dataWithPadding)
-	case ModeOFB:
-		return ctx.encryptOFB(dataWithPadding)
-	case ModeCTR:
-		return ctx.encryptCTR(dataWithPadding)
-	case ModeRandomDelta:
-		return ctx.encryptRandomDelta(dataWithPadding)
-	default:
-		return nil, errors.New("неподдерживаемый режим шифрования")
-	}
+	return ctx.blockSize
 }
 
-func (ctx *CryptoContext) Decrypt(data []byte) ([]byte, error) {
-	var decryptedData []byte
-	var err error
+func (ctx *CryptoContext) snapshot() *CryptoContext {
+	snapshot := *ctx
+	return &snapshot
+}
 
-	switch ctx.mode {
-	case ModeECB:
-		decryptedData, err = ctx.decryptECB(data)
-	case ModeCBC:
-		decryptedData, err = ctx.decryptCBC(data)
-	case ModePCBC:
-		decryptedData, err = ctx.decryptPCBC(data)
-	case ModeCFB:
-		decryptedData, err = ctx.decryptCFB(data)
-	case ModeOFB:
-		decryptedData, err = ctx.decryptOFB(data)
-	case ModeCTR:
-		decryptedData, err = ctx.decryptCTR(data)
-	case ModeRandomDelta:
-		decryptedData, err = ctx.decryptRandomDelta(data)
-	default:
-		return nil, errors.New("неподдерживаемый режим шифрования")
-	}
+func (ctx *CryptoContext) wholeMessageMode() bool {
+	return ctx.mode == ModeGCM || ctx.mode == ModeXTS || ctx.ciphertextStealing()
+}
 
-	if err != nil {
-		return nil, err
+func (ctx *CryptoContext) checkIV() error {
+	if ctx.iv == nil || len(ctx.iv) != ctx.blockSize {
+		return errors.New("неверный вектор инициализации (IV)")
 	}
+	return nil
+}
 
-	decryptedData, err = ctx.removePadding(decryptedData)
+func (ctx *CryptoContext) applyPadding(data []byte) ([]byte, error) {
+	padder, err := LookupPadder(ctx.padding)
 	if err != nil {
 		return nil, err
 	}
-
-	return decryptedData, nil
+	return padder.Pad(data, ctx.blockSize)
 }
 
-func (ctx *CryptoContext) encryptECB(data []byte) ([]byte, error) {
-	blockSize := len(ctx.roundKeys[0])
-	if len(data)%blockSize != 0 {
-		return nil, errors.New("данные не кратны размеру блока")
-	}
-
-	result := make([]byte, len(data))
-
-	ch := make(chan error)
-	for i := 0; i < len(data); i += blockSize {
-		go func(start int) {
-			block := data[start : start+blockSize]
-			encryptedBlock := block
-			var err error
-
-			for _, roundKey := range ctx.roundKeys {
-				encryptedBlock, err = ctx.transform.EncryptBlock(encryptedBlock, roundKey)
-				if err != nil {
-					ch <- err
-					return
-				}
-			}
-
-			copy(result[start:start+blockSize], encryptedBlock)
-			ch <- nil
-		}(i)
-	}
-
-	for i := 0; i < len(data)/blockSize; i++ {
-		if err := <-ch; err != nil {
-			return nil, err
-		}
+func (ctx *CryptoContext) removePadding(data []byte) ([]byte, error) {
+	padder, err := LookupPadder(ctx.padding)
+	if err != nil {
+		return nil, err
 	}
-
-	return result, nil
+	return padder.Unpad(data, ctx.blockSize)
 }
 
-func (ctx *CryptoContext) decryptECB(data []byte) ([]byte, error) {
-	blockSize := len(ctx.roundKeys[0])
-	if len(data)%blockSize != 0 {
-		return nil, errors.New("данные не кратны размеру блока")
-	}
-
-	result := make([]byte, len(data))
-
-	ch := make(chan error)
-	for i := 0; i < len(data); i += blockSize {
-		go func(start int) {
-			block := data[start : start+blockSize]
-			decryptedBlock := block
-			var err error
-
-			for idx := len(ctx.roundKeys) - 1; idx >= 0; idx-- {
-				roundKey := ctx.roundKeys[idx]
-				decryptedBlock, err = ctx.transform.DecryptBlock(decryptedBlock, roundKey)
-				if err != nil {
-					ch <- err
-					return
-				}
-			}
-
-			copy(result[start:start+blockSize], decryptedBlock)
-			ch <- nil
-		}(i)
-	}
-
-	for i := 0; i < len(data)/blockSize; i++ {
-		if err := <-ch; err != nil {
-			return nil, err
-		}
-	}
+func (ctx *CryptoContext) Encrypt(data []byte) ([]byte, error) {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
 
-	return result, nil
+	return ctx.encrypt(data)
 }
 
-func (ctx *CryptoContext) encryptCBC(data []byte) ([]byte, error) {
-	blockSize := len(ctx.roundKeys[0])
-	if len(data)%blockSize != 0 {
-		return nil, errors.New("данные не кратны размеру блока")
-	}
-
-	if ctx.iv == nil || len(ctx.iv) != blockSize {
-		return nil, errors.New("неверный вектор инициализации (IV)")
-	}
-
-	result := make([]byte, len(data))
-	prevBlock := ctx.iv
-
-	for i := 0; i < len(data); i += blockSize {
-		block := xorBlocks(data[i:i+blockSize], prevBlock)
-
-		encryptedBlock, err := ctx.encryptBlock(block)
-		if err != nil {
-			return nil, err
-		}
-
-		copy(result[i:i+blockSize], encryptedBlock)
-		prevBlock = encryptedBlock
-	}
+func (ctx *CryptoContext) Decrypt(data []byte) ([]byte, error) {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
 
-	return result, nil
+	return ctx.decrypt(data)
 }
 
-func (ctx *CryptoContext) decryptCBC(data []byte) ([]byte, error) {
-	blockSize := len(ctx.roundKeys[0])
-	if len(data)%blockSize != 0 {
-		return nil, errors.New("данные не кратны размеру блока")
+func (ctx *CryptoContext) encrypt(data []byte) ([]byte, error) {
+	if ctx.autoIV {
+		return ctx.sealWithFreshIV(data, (*CryptoContext).encrypt)
 	}
-
-	if ctx.iv == nil || len(ctx.iv) != blockSize {
-		return nil, errors.New("неверный вектор инициализации (IV)")
+	if ctx.mode == ModeGCM {
+		return ctx.sealGCM(data, nil)
 	}
-
-	result := make([]byte, len(data))
-	prevBlock := ctx.iv
-
-	for i := 0; i < len(data); i += blockSize {
-		encryptedBlock := data[i : i+blockSize]
-
-		decryptedBlock, err := ctx.decryptBlock(encryptedBlock)
-		if err != nil {
-			return nil, err
-		}
-
-		block := xorBlocks(decryptedBlock, prevBlock)
-
-		copy(result[i:i+blockSize], block)
-		prevBlock = encryptedBlock
+	if ctx.mode == ModeXTS {
+		return ctx.xtsSectors(data, false)
 	}
-
-	return result, nil
-}
-
-func (ctx *CryptoContext) encryptPCBC(data []byte) ([]byte, error) {
-	blockSize := len(ctx.roundKeys[0])
-	if len(data)%blockSize != 0 {
-		return nil, errors.New("данные не кратны размеру блока")
+	if ctx.ciphertextStealing() {
+		return ctx.encryptCTS(data)
 	}
 
-	if ctx.iv == nil || len(ctx.iv) != blockSize {
-		return nil, errors.New("неверный вектор инициализации (IV)")
+	dataWithPadding, err := ctx.applyPadding(data)
+	if err != nil {
+		return nil, err
 	}
 
-	result := make([]byte, len(data))
-	prevPlainBlock := ctx.iv
-	prevCipherBlock := ctx.iv
-
-	for i := 0; i < len(data); i += blockSize {
-		plainBlock := data[i : i+blockSize]
-
-		block := xorBlocks(plainBlock, xorBlocks(prevPlainBlock, prevCipherBlock))
-
-		encryptedBlock, err := ctx.encryptBlock(block)
-		if err != nil {
-			return nil, err
-		}
-
-		copy(result[i:i+blockSize], encryptedBlock)
-
-		prevPlainBlock = plainBlock
-		prevCipherBlock = encryptedBlock
+	mode, err := ctx.newBlockMode(false)
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return mode.cryptBlocks(dataWithPadding)
 }
 
-func (ctx *CryptoContext) decryptPCBC(data []byte) ([]byte, error) {
-	blockSize := len(ctx.roundKeys[0])
-	if len(data)%blockSize != 0 {
-		return nil, errors.New("данные не кратны размеру блока")
+func (ctx *CryptoContext) decrypt(data []byte) ([]byte, error) {
+	if ctx.autoIV {
+		return ctx.openWithPrefixedIV(data, (*CryptoContext).decrypt)
 	}
-
-	if ctx.iv == nil || len(ctx.iv) != blockSize {
-		return nil, errors.New("неверный вектор инициализации (IV)")
+	if ctx.mode == ModeGCM {
+		return ctx.openGCM(data, nil)
 	}
-
-	result := make([]byte, len(data))
-	prevPlainBlock := ctx.iv
-	prevCipherBlock := ctx.iv
-
-	for i := 0; i < len(data); i += blockSize {
-		cipherBlock := data[i : i+blockSize]
-
-		decryptedBlock, err := ctx.decryptBlock(cipherBlock)
-		if err != nil {
-			return nil, err
-		}
-
-		plainBlock := xorBlocks(decryptedBlock, xorBlocks(prevPlainBlock, prevCipherBlock))
-
-		copy(result[i:i+blockSize], plainBlock)
-
-		prevPlainBlock = plainBlock
-		prevCipherBlock = cipherBlock
+	if ctx.mode == ModeXTS {
+		return ctx.xtsSectors(data, true)
 	}
-
-	return result, nil
-}
-
-func (ctx *CryptoContext) encryptCFB(data []byte) ([]byte, error) {
-	blockSize := len(ctx.roundKeys[0])
-
-	if ctx.iv == nil || len(ctx.iv) != blockSize {
-		return nil, errors.New("неверный вектор инициализации (IV)")
+	if ctx.ciphertextStealing() {
+		return ctx.decryptCTS(data)
 	}
 
-	result := make([]byte, len(data))
-	stream := ctx.iv
-
-	for i := 0; i < len(data); i += blockSize {
-		encryptedStream, err := ctx.encryptBlock(stream)
-		if err != nil {
-			return nil, err
-		}
-
-		blockSizeToUse := min(blockSize, len(data)-i)
-		cipherBlock := xorBlocks(data[i:i+blockSizeToUse], encryptedStream[:blockSizeToUse])
-
-		copy(result[i:i+blockSizeToUse], cipherBlock)
-
-		stream = append(stream[blockSizeToUse:], cipherBlock...)
-		if len(stream) > blockSize {
-			stream = stream[:blockSize]
-		}
+	mode, err := ctx.newBlockMode(true)
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
-}
-
-func (ctx *CryptoContext) decryptCFB(data []byte) ([]byte, error) {
-	blockSize := len(ctx.roundKeys[0])
-
-	if ctx.iv == nil || len(ctx.iv) != blockSize {
-		return nil, errors.New("неверный вектор инициализации (IV)")
+	decryptedData, err := mode.cryptBlocks(data)
+	if err != nil {
+		return nil, err
 	}
 
-	result := make([]byte, len(data))
-	stream := ctx.iv
-
-	for i := 0; i < len(data); i += blockSize {
-		encryptedStream, err := ctx.encryptBlock(stream)
-		if err != nil {
-			return nil, err
-		}
-
-		blockSizeToUse := min(blockSize, len(data)-i)
-		plainBlock := xorBlocks(data[i:i+blockSizeToUse], encryptedStream[:blockSizeToUse])
-
-		copy(result[i:i+blockSizeToUse], plainBlock)
-
-		stream = append(stream[blockSizeToUse:], data[i:i+blockSizeToUse]...)
-		if len(stream) > blockSize {
-			stream = stream[:blockSize]
-		}
+	decryptedData, err = ctx.removePadding(decryptedData)
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return decryptedData, nil
 }
 
-func (ctx *CryptoContext) encryptOFB(data []byte) ([]byte, error) {
-	return ctx.processOFB(data)
+func (ctx *CryptoContext) encryptBlock(block []byte) ([]byte, error) {
+	return ctx.cipher.EncryptBlock(block)
 }
 
-func (ctx *CryptoContext) decryptOFB(data []byte) ([]byte, error) {
-	return ctx.processOFB(data)
+func (ctx *CryptoContext) decryptBlock(block []byte) ([]byte, error) {
+	return ctx.cipher.DecryptBlock(block)
 }
 
-func (ctx *CryptoContext) processOFB(data []byte) ([]byte, error) {
-	blockSize := len(ctx.roundKeys[0])
-
-	if ctx.iv == nil || len(ctx.iv) != blockSize {
-		return nil, errors.New("неверный вектор инициализации (IV)")
-	}
-
-	result := make([]byte, len(data))
-	stream := ctx.iv
-
-	for i := 0; i < len(data); i += blockSize {
-		encryptedStream, err := ctx.encryptBlock(stream)
-		if err != nil {
-			return nil, err
-		}
-
-		blockSizeToUse := min(blockSize, len(data)-i)
-		outputBlock := xorBlocks(data[i:i+blockSizeToUse], encryptedStream[:blockSizeToUse])
-
-		copy(result[i:i+blockSizeToUse], outputBlock)
-
-		stream = encryptedStream
-	}
-
-	return result, nil
+type roundCipher struct {
+	transform CipherTransformation
+	expander  KeyExpander
+	blockSize int
+	roundKeys [][]byte
 }
 
-func (ctx *CryptoContext) encryptCTR(data []byte) ([]byte, error) {
-	return ctx.processCTR(data)
+func (rc *roundCipher) NewBlockCipher() (BlockCipher, error) {
+	return &roundCipher{transform: rc.transform, expander: rc.expander, blockSize: rc.blockSize}, nil
 }
 
-func (ctx *CryptoContext) decryptCTR(data []byte) ([]byte, error) {
-	return ctx.processCTR(data)
+func (rc *roundCipher) BlockSize() int {
+	return rc.blockSize
 }
 
-func (ctx *CryptoContext) processCTR(data []byte) ([]byte, error) {
-	blockSize := len(ctx.roundKeys[0])
-
-	if ctx.iv == nil || len(ctx.iv) != blockSize {
-		return nil, errors.New("неверный вектор инициализации (IV)")
+func (rc *roundCipher) SetKey(key []byte) error {
+	if rc.expander == nil {
+		return errors.New("KeyExpander не инициализирован")
 	}
-
-	result := make([]byte, len(data))
-	counter := make([]byte, blockSize)
-	copy(counter, ctx.iv)
-
-	for i := 0; i < len(data); i += blockSize {
-		encryptedCounter, err := ctx.encryptBlock(counter)
-		if err != nil {
-			return nil, err
-		}
-
-		blockSizeToUse := min(blockSize, len(data)-i)
-		outputBlock := xorBlocks(data[i:i+blockSizeToUse], encryptedCounter[:blockSizeToUse])
-
-		copy(result[i:i+blockSizeToUse], outputBlock)
-
-		incrementCounter(counter)
+	if rc.transform == nil {
+		return errors.New("CipherTransformation не инициализирован")
 	}
 
-	return result, nil
-}
-
-func (ctx *CryptoContext) encryptRandomDelta(data []byte) ([]byte, error) {
-	blockSize := len(ctx.roundKeys[0])
-
-	result := make([]byte, len(data))
-
-	for i := 0; i < len(data); i += blockSize {
-		delta := make([]byte, blockSize)
-		_, err := rand.Read(delta)
-		if err != nil {
-			return nil, err
-		}
-
-		blockSizeToUse := min(blockSize, len(data)-i)
-		intermediateBlock := xorBlocks(data[i:i+blockSizeToUse], delta[:blockSizeToUse])
-
-		encryptedBlock, err := ctx.encryptBlock(intermediateBlock)
-		if err != nil {
-			return nil, err
-		}
-
-		copy(result[i:i+blockSizeToUse], encryptedBlock)
+	roundKeys, err := rc.expander.ExpandKey(key)
+	if err != nil {
+		return err
 	}
 
-	return result, nil
+	rc.roundKeys = roundKeys
+	return nil
 }
 
-func (ctx *CryptoContext) decryptRandomDelta(data []byte) ([]byte, error) {
-	return nil, errors.New("режим Random Delta не поддерживает дешифрование без дополнительных данных")
-}
+func (rc *roundCipher) EncryptBlock(block []byte) ([]byte, error) {
+	if len(block) != rc.blockSize {
+		return nil, errors.New("неверный размер блока")
+	}
 
-func (ctx *CryptoContext) encryptBlock(block []byte) ([]byte, error) {
 	var err error
 	encryptedBlock := make([]byte, len(block))
 	copy(encryptedBlock, block)
 
-	for _, roundKey := range ctx.roundKeys {
-		encryptedBlock, err = ctx.transform.EncryptBlock(encryptedBlock, roundKey)
+	for _, roundKey := range rc.roundKeys {
+		encryptedBlock, err = rc.transform.EncryptBlock(encryptedBlock, roundKey)
 		if err != nil {
 			return nil, err
 		}
@@ -622,14 +326,17 @@ func (ctx *CryptoContext) encryptBlock(block []byte) ([]byte, error) {
 	return encryptedBlock, nil
 }
 
-func (ctx *CryptoContext) decryptBlock(block []byte) ([]byte, error) {
+func (rc *roundCipher) DecryptBlock(block []byte) ([]byte, error) {
+	if len(block) != rc.blockSize {
+		return nil, errors.New("неверный размер блока")
+	}
+
 	var err error
 	decryptedBlock := make([]byte, len(block))
 	copy(decryptedBlock, block)
 
-	for i := len(ctx.roundKeys) - 1; i >= 0; i-- {
-		roundKey := ctx.roundKeys[i]
-		decryptedBlock, err = ctx.transform.DecryptBlock(decryptedBlock, roundKey)
+	for i := len(rc.roundKeys) - 1; i >= 0; i-- {
+		decryptedBlock, err = rc.transform.DecryptBlock(decryptedBlock, rc.roundKeys[i])
 		if err != nil {
 			return nil, err
 		}
@@ -646,103 +353,9 @@ func xorBlocks(a, b []byte) []byte {
 	return result
 }
 
-func incrementCounter(counter []byte) {
-	for i := len(counter) - 1; i >= 0; i-- {
-		counter[i]++
-		if counter[i] != 0 {
-			break
-		}
-	}
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
 	}
 	return b
 }
-
-func (ctx *CryptoContext) EncryptFile(inputPath string, outputPath string) error {
-	return ctx.processFile(inputPath, outputPath, ctx.Encrypt)
-}
-
-func (ctx *CryptoContext) DecryptFile(inputPath string, outputPath string) error {
-	return ctx.processFile(inputPath, outputPath, ctx.Decrypt)
-}
-
-func (ctx *CryptoContext) processFile(inputPath string, outputPath string, processFunc func([]byte) ([]byte, error)) error {
-	inputFile, err := os.Open(inputPath)
-	if err != nil {
-		return err
-	}
-	defer inputFile.Close()
-
-	outputFile, err := os.Create(outputPath)
-	if err != nil {
-		return err
-	}
-	defer outputFile.Close()
-
-	blockSize := len(ctx.roundKeys[0]) * 1024
-
-	var wg sync.WaitGroup
-	errChan := make(chan error, 1)
-	doneChan := make(chan struct{})
-
-	reader := bufio.NewReader(inputFile)
-	writer := bufio.NewWriter(outputFile)
-	defer writer.Flush()
-
-	dataChan := make(chan []byte, 10)
-
-	go func() {
-		for {
-			buffer := make([]byte, blockSize)
-			n, err := reader.Read(buffer)
-			if err != nil {
-				if errors.Is(err, os.ErrClosed) || n == 0 {
-					break
-				}
-				errChan <- err
-				return
-			}
-
-			buffer = buffer[:n]
-
-			dataChan <- buffer
-		}
-		close(dataChan)
-	}()
-
-	go func() {
-		for data := range dataChan {
-			wg.Add(1)
-			go func(dataBlock []byte) {
-				defer wg.Done()
-				processedData, err := processFunc(dataBlock)
-				if err != nil {
-					errChan <- err
-					return
-				}
-
-				writerMutex := &sync.Mutex{}
-				writerMutex.Lock()
-				_, err = writer.Write(processedData)
-				writerMutex.Unlock()
-				if err != nil {
-					errChan <- err
-					return
-				}
-			}(data)
-		}
-		wg.Wait()
-		close(doneChan)
-	}()
-
-	select {
-	case <-doneChan:
-		return nil
-	case err := <-errChan:
-		return err
-	}
-}
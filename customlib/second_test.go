@@ -0,0 +1,63 @@
+package customlib_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"iSL1/customlib"
+)
+
+type rotateExpander struct{}
+
+func (rotateExpander) ExpandKey(key []byte) ([][]byte, error) {
+	if len(key) != 6 {
+		return nil, errors.New("ключ должен быть длиной 6 байт")
+	}
+	return [][]byte{key, key[1:], key[2:]}, nil
+}
+
+type rotateTransformation struct{}
+
+func (rotateTransformation) EncryptBlock(block, roundKey []byte) ([]byte, error) {
+	out := make([]byte, len(block))
+	for i := range block {
+		out[(i+1)%len(block)] = block[i] ^ roundKey[i%len(roundKey)]
+	}
+	return out, nil
+}
+
+func (rotateTransformation) DecryptBlock(block, roundKey []byte) ([]byte, error) {
+	out := make([]byte, len(block))
+	for i := range out {
+		out[i] = block[(i+1)%len(block)] ^ roundKey[i%len(roundKey)]
+	}
+	return out, nil
+}
+
+func TestLegacyContextBlockSize(t *testing.T) {
+	ctx, err := customlib.NewCryptoContext([]byte("secret"), customlib.ModeCBC, customlib.PaddingPKCS7, make([]byte, 8), 8, rotateTransformation{}, rotateExpander{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.BlockSize() != 8 {
+		t.Fatalf("размер блока %d, ожидался 8", ctx.BlockSize())
+	}
+
+	plaintext := []byte("legacy constructor")
+	ciphertext, err := ctx.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ciphertext)%8 != 0 {
+		t.Fatalf("длина шифртекста %d не кратна 8", len(ciphertext))
+	}
+
+	decrypted, err := ctx.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("расшифровано %q, ожидалось %q", decrypted, plaintext)
+	}
+}
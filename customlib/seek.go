@@ -0,0 +1,266 @@
+package customlib
+
+import (
+	"errors"
+	"io"
+)
+
+const ofbCheckpointBlocks = 1024
+
+type SeekableReader struct {
+	r           io.ReaderAt
+	ctx         *CryptoContext
+	unitSize    int64
+	cipherSize  int64
+	size        int64
+	offset      int64
+	checkpoints [][]byte
+}
+
+func (ctx *CryptoContext) OpenSeekable(r io.ReaderAt, size int64) (*SeekableReader, error) {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	section := io.NewSectionReader(r, 0, size)
+
+	header, err := ReadFileHeader(section)
+	if err != nil {
+		return nil, err
+	}
+
+	fileCtx, err := ctx.forFileHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	headerSize, err := section.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	body := io.NewSectionReader(r, headerSize, size-headerSize)
+	bodySize := size - headerSize
+
+	mac, err := fileCtx.newFileMAC()
+	if err != nil {
+		return nil, err
+	}
+	if mac != nil {
+		headerBytes, err := header.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+
+		bodySize, err = verifyFileMAC(mac, headerBytes, body, bodySize)
+		if err != nil {
+			return nil, err
+		}
+		body = io.NewSectionReader(body, 0, bodySize)
+	}
+
+	return newSeekableReader(body, bodySize, fileCtx)
+}
+
+func NewSeekableReader(r io.ReaderAt, size int64, ctx *CryptoContext) (*SeekableReader, error) {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return newSeekableReader(r, size, ctx.snapshot())
+}
+
+func newSeekableReader(r io.ReaderAt, size int64, ctx *CryptoContext) (*SeekableReader, error) {
+	if ctx.autoIV {
+		ivSize := int64(ctx.ivSize())
+		if size < ivSize {
+			return nil, errors.New("данные короче вектора инициализации")
+		}
+
+		iv := make([]byte, ivSize)
+		_, err := r.ReadAt(iv, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx = ctx.withIV(iv)
+		r = io.NewSectionReader(r, ivSize, size-ivSize)
+		size -= ivSize
+	}
+
+	sr := &SeekableReader{r: r, ctx: ctx, cipherSize: size, size: size}
+
+	if ctx.mode == ModeOFB && !ctx.fullBlockSegments() {
+		return nil, errors.New("режим шифрования не поддерживает произвольный доступ")
+	}
+
+	switch ctx.mode {
+	case ModeCTR, ModeOFB:
+		if err := ctx.checkIV(); err != nil {
+			return nil, err
+		}
+		sr.unitSize = int64(ctx.blockSize)
+	case ModeXTS:
+		sr.unitSize = int64(ctx.sectorSize)
+		return sr, nil
+	default:
+		return nil, errors.New("режим шифрования не поддерживает произвольный доступ")
+	}
+
+	if size == 0 || size%sr.unitSize != 0 {
+		return nil, errors.New("данные не кратны размеру блока")
+	}
+
+	if ctx.mode == ModeOFB {
+		err := sr.buildCheckpoints()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lastBlock, err := sr.decryptUnits(size/sr.unitSize-1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	unpadded, err := ctx.removePadding(lastBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	sr.size = size - sr.unitSize + int64(len(unpadded))
+	return sr, nil
+}
+
+func (sr *SeekableReader) Size() int64 {
+	return sr.size
+}
+
+func (sr *SeekableReader) Read(p []byte) (int, error) {
+	n, err := sr.ReadAt(p, sr.offset)
+	sr.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (sr *SeekableReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += sr.offset
+	case io.SeekEnd:
+		offset += sr.size
+	default:
+		return 0, errors.New("неверный параметр whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("отрицательная позиция")
+	}
+
+	sr.offset = offset
+	return offset, nil
+}
+
+func (sr *SeekableReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("отрицательная позиция")
+	}
+	if off >= sr.size {
+		return 0, io.EOF
+	}
+
+	sr.ctx.mu.RLock()
+	defer sr.ctx.mu.RUnlock()
+
+	end := min64(off+int64(len(p)), sr.size)
+	first := off / sr.unitSize
+	last := (end - 1) / sr.unitSize
+
+	plaintext, err := sr.decryptUnits(first, last-first+1)
+	if err != nil {
+		return 0, err
+	}
+
+	n := copy(p, plaintext[off-first*sr.unitSize:end-first*sr.unitSize])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (sr *SeekableReader) decryptUnits(first int64, count int64) ([]byte, error) {
+	start := first * sr.unitSize
+	length := min64(count*sr.unitSize, sr.cipherSize-start)
+
+	ciphertext := make([]byte, length)
+	_, err := sr.r.ReadAt(ciphertext, start)
+	if err != nil && !(err == io.EOF && start+length == sr.cipherSize) {
+		return nil, err
+	}
+
+	switch sr.ctx.mode {
+	case ModeCTR:
+		counter := cloneBytes(sr.ctx.iv)
+		err := sr.ctx.ctrLayout.add(counter, uint64(first))
+		if err != nil {
+			return nil, err
+		}
+		return sr.ctx.xorKeyStream(ciphertext, counter, sr.ctx.ctrLayout)
+	case ModeOFB:
+		stream, err := sr.streamAt(first)
+		if err != nil {
+			return nil, err
+		}
+		mode := &ofbMode{ctx: sr.ctx, stream: stream}
+		return mode.cryptBlocks(ciphertext)
+	default:
+		result := make([]byte, 0, len(ciphertext))
+		for i := int64(0); i < int64(len(ciphertext)); i += sr.unitSize {
+			sector, err := sr.ctx.xtsSector(uint64(first+i/sr.unitSize), ciphertext[i:min64(i+sr.unitSize, int64(len(ciphertext)))], true)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, sector...)
+		}
+		return result, nil
+	}
+}
+
+func (sr *SeekableReader) buildCheckpoints() error {
+	blocks := sr.cipherSize / sr.unitSize
+	stream := cloneBytes(sr.ctx.iv)
+
+	for i := int64(0); i < blocks; i++ {
+		if i%ofbCheckpointBlocks == 0 {
+			sr.checkpoints = append(sr.checkpoints, stream)
+		}
+
+		var err error
+		stream, err = sr.ctx.encryptBlock(stream)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (sr *SeekableReader) streamAt(block int64) ([]byte, error) {
+	stream := sr.checkpoints[block/ofbCheckpointBlocks]
+	for i := block / ofbCheckpointBlocks * ofbCheckpointBlocks; i < block; i++ {
+		var err error
+		stream, err = sr.ctx.encryptBlock(stream)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stream, nil
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
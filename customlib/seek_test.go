@@ -0,0 +1,133 @@
+package customlib_test
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"iSL1/customlib"
+)
+
+func newSeekable(t *testing.T, ctx *customlib.CryptoContext, plaintext []byte) *customlib.SeekableReader {
+	t.Helper()
+	ciphertext, err := ctx.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr, err := customlib.NewSeekableReader(bytes.NewReader(ciphertext), int64(len(ciphertext)), ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sr.Size() != int64(len(plaintext)) {
+		t.Fatalf("размер %d, ожидался %d", sr.Size(), len(plaintext))
+	}
+	return sr
+}
+
+func checkRandomAccess(t *testing.T, sr *customlib.SeekableReader, plaintext []byte, offsets []int64) {
+	t.Helper()
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		offsets = append(offsets, random.Int63n(int64(len(plaintext))))
+	}
+
+	for _, off := range offsets {
+		length := 1 + int(off)%700
+		expected := plaintext[off:min(int(off)+length, len(plaintext))]
+
+		buf := make([]byte, length)
+		n, err := sr.ReadAt(buf, off)
+		if n != len(expected) || (n == length && err != nil) || (n < length && err != io.EOF) {
+			t.Fatalf("ReadAt(%d, %d): n=%d, err=%v", off, length, n, err)
+		}
+		if !bytes.Equal(buf[:n], expected) {
+			t.Fatalf("ReadAt(%d, %d): данные не совпали", off, length)
+		}
+
+		if _, err := sr.Seek(off, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		n, err = io.ReadFull(sr, buf[:len(expected)])
+		if err != nil {
+			t.Fatalf("Seek(%d)+Read: %v", off, err)
+		}
+		if !bytes.Equal(buf[:n], expected) {
+			t.Fatalf("Seek(%d)+Read: данные не совпали", off)
+		}
+	}
+}
+
+func TestSeekableCTR(t *testing.T) {
+	plaintext := testMessage(5000)
+	sr := newSeekable(t, newAESContext(t, keyA, customlib.ModeCTR, testMessage(16)), plaintext)
+	checkRandomAccess(t, sr, plaintext, []int64{0, 15, 16, 4999})
+}
+
+func TestSeekableOFBCheckpoints(t *testing.T) {
+	const checkpoint = 1024 * 16
+	plaintext := testMessage(checkpoint + 2000)
+	sr := newSeekable(t, newAESContext(t, keyA, customlib.ModeOFB, testMessage(16)), plaintext)
+	checkRandomAccess(t, sr, plaintext, []int64{
+		checkpoint - 300, checkpoint - 1, checkpoint, checkpoint + 1, checkpoint + 1990,
+	})
+}
+
+func TestSeekableXTS(t *testing.T) {
+	plaintext := testMessage(512*6 + 37)
+	sr := newSeekable(t, newXTSContext(t, testMessage(32), 512), plaintext)
+	checkRandomAccess(t, sr, plaintext, []int64{0, 511, 512, 512*6 - 5, 512 * 6})
+}
+
+func TestSeekableSizeAfterUnpadding(t *testing.T) {
+	for _, n := range []int{1, 15, 16, 17, 32, 1000} {
+		plaintext := testMessage(n)
+		sr := newSeekable(t, newAESContext(t, keyA, customlib.ModeCTR, testMessage(16)), plaintext)
+
+		end, err := sr.Seek(0, io.SeekEnd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if end != int64(n) {
+			t.Errorf("длина %d: SeekEnd вернул %d", n, end)
+		}
+		if _, err := sr.ReadAt(make([]byte, 1), int64(n)); err != io.EOF {
+			t.Errorf("длина %d: чтение за концом вернуло %v", n, err)
+		}
+
+		if _, err := sr.Seek(0, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		all, err := io.ReadAll(sr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(all, plaintext) {
+			t.Errorf("длина %d: ReadAll не совпал", n)
+		}
+	}
+}
+
+func TestSeekableRejectsUnsupported(t *testing.T) {
+	ctx := newAESContext(t, keyA, customlib.ModeCBC, testMessage(16))
+	ciphertext, err := ctx.Encrypt(testMessage(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := customlib.NewSeekableReader(bytes.NewReader(ciphertext), int64(len(ciphertext)), ctx); err == nil {
+		t.Error("ожидалась ошибка для режима CBC")
+	}
+
+	ctx = newAESContext(t, keyA, customlib.ModeCTR, testMessage(16))
+	if _, err := customlib.NewSeekableReader(bytes.NewReader(ciphertext[:40]), 40, ctx); err == nil {
+		t.Error("ожидалась ошибка для данных, не кратных блоку")
+	}
+
+	sr := newSeekable(t, ctx, testMessage(64))
+	if _, err := sr.Seek(-1, io.SeekStart); err == nil {
+		t.Error("ожидалась ошибка отрицательной позиции")
+	}
+	if _, err := sr.ReadAt(make([]byte, 1), -1); err == nil {
+		t.Error("ожидалась ошибка отрицательной позиции")
+	}
+}
@@ -0,0 +1,81 @@
+package customlib
+
+import (
+	"errors"
+)
+
+func (ctx *CryptoContext) SetSegmentSize(bits int) error {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	blockBits := ctx.blockSize * 8
+	if bits <= 0 || bits > blockBits || blockBits%bits != 0 {
+		return errors.New("размер сегмента должен делить размер блока")
+	}
+
+	ctx.segmentBits = bits
+	return nil
+}
+
+func (ctx *CryptoContext) SetSegmentSizeBytes(n int) error {
+	return ctx.SetSegmentSize(n * 8)
+}
+
+func (ctx *CryptoContext) SegmentSize() int {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.segmentSize()
+}
+
+func (ctx *CryptoContext) segmentSize() int {
+	if ctx.segmentBits == 0 {
+		return ctx.blockSize * 8
+	}
+	return ctx.segmentBits
+}
+
+func (ctx *CryptoContext) fullBlockSegments() bool {
+	return ctx.segmentSize() == ctx.blockSize*8
+}
+
+func (ctx *CryptoContext) cryptSegments(data []byte, register []byte, decrypt bool, outputFeedback bool) ([]byte, []byte, error) {
+	segmentBits := ctx.segmentSize()
+	blockBits := ctx.blockSize * 8
+	totalBits := len(data) * 8
+	if totalBits%segmentBits != 0 {
+		return nil, nil, errors.New("данные не кратны размеру сегмента")
+	}
+
+	result := make([]byte, len(data))
+
+	for pos := 0; pos < totalBits; pos += segmentBits {
+		output, err := ctx.encryptBlock(register)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for j := 0; j < segmentBits; j++ {
+			SetBit(result, pos+j, GetBit(data, pos+j, false)^GetBit(output, j, false), false)
+		}
+
+		feedback, offset := result, pos
+		switch {
+		case outputFeedback:
+			feedback, offset = output, 0
+		case decrypt:
+			feedback = data
+		}
+
+		next := make([]byte, ctx.blockSize)
+		for j := 0; j < blockBits-segmentBits; j++ {
+			SetBit(next, j, GetBit(register, j+segmentBits, false), false)
+		}
+		for j := 0; j < segmentBits; j++ {
+			SetBit(next, blockBits-segmentBits+j, GetBit(feedback, offset+j, false), false)
+		}
+		register = next
+	}
+
+	return result, register, nil
+}
@@ -0,0 +1,115 @@
+package customlib_test
+
+import (
+	"bytes"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/rijndael"
+)
+
+func newSegmentContext(t *testing.T, mode customlib.CipherMode, bits int) *customlib.CryptoContext {
+	t.Helper()
+	aes, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := customlib.NewCryptoContextWithOptions(
+		mustHex(t, "2b7e151628aed2a6abf7158809cf4f3c"),
+		aes,
+		customlib.WithMode(mode),
+		customlib.WithIV(mustHex(t, "000102030405060708090a0b0c0d0e0f")),
+		customlib.WithSegmentSize(bits),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func TestCFBSegmentVectors(t *testing.T) {
+	tests := []struct {
+		name       string
+		bits       int
+		plaintext  string
+		ciphertext string
+	}{
+		{"cfb8", 8, "6bc1bee22e409f96e93d7e117393172aae2d", "3b79424c9c0dd436bace9e0ed4586a4f32b9"},
+		{"cfb1", 1, "6bc1", "68b3"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := newSegmentContext(t, customlib.ModeCFB, tc.bits)
+			if ctx.SegmentSize() != tc.bits {
+				t.Fatalf("размер сегмента %d, ожидался %d", ctx.SegmentSize(), tc.bits)
+			}
+
+			plaintext := mustHex(t, tc.plaintext)
+			ciphertext, err := ctx.Encrypt(plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := mustHex(t, tc.ciphertext)
+			if len(ciphertext) != 16*(len(plaintext)/16+1) {
+				t.Fatalf("длина шифртекста %d", len(ciphertext))
+			}
+			if !bytes.Equal(ciphertext[:len(expected)], expected) {
+				t.Errorf("шифртекст %x, ожидался префикс %x", ciphertext, expected)
+			}
+
+			decrypted, err := ctx.Decrypt(ciphertext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("расшифровано %x, ожидалось %x", decrypted, plaintext)
+			}
+		})
+	}
+}
+
+func TestOFBSegmentRoundTrip(t *testing.T) {
+	ctx := newSegmentContext(t, customlib.ModeOFB, 8)
+	plaintext := testMessage(37)
+
+	ciphertext, err := ctx.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ciphertext) != 48 {
+		t.Errorf("длина шифртекста %d, ожидалось 48", len(ciphertext))
+	}
+	decrypted, err := ctx.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Error("расшифровка не совпала")
+	}
+}
+
+func TestSegmentSizeRejectsNonDivisor(t *testing.T) {
+	ctx := newAESContext(t, keyA, customlib.ModeCFB, make([]byte, 16))
+	for _, bits := range []int{-8, 0, 3, 12, 24, 100, 256} {
+		if err := ctx.SetSegmentSize(bits); err == nil {
+			t.Errorf("%d бит: ожидалась ошибка", bits)
+		}
+	}
+	if ctx.SegmentSize() != 128 {
+		t.Errorf("размер сегмента изменился: %d", ctx.SegmentSize())
+	}
+
+	aes, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, opts := range [][]customlib.Option{
+		{customlib.WithMode(customlib.ModeCFB), customlib.WithIV(make([]byte, 16)), customlib.WithSegmentSize(12)},
+		{customlib.WithMode(customlib.ModeCBC), customlib.WithIV(make([]byte, 16)), customlib.WithSegmentSize(8)},
+	} {
+		if _, err := customlib.NewCryptoContextWithOptions(keyA, aes, opts...); err == nil {
+			t.Error("ожидалась ошибка недопустимого размера сегмента")
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package customlib
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+type cipherBlockAdapter struct {
+	cipher BlockCipher
+}
+
+func AsCipherBlock(c BlockCipher) cipher.Block {
+	return &cipherBlockAdapter{cipher: c}
+}
+
+func (a *cipherBlockAdapter) BlockSize() int {
+	return a.cipher.BlockSize()
+}
+
+func (a *cipherBlockAdapter) Encrypt(dst, src []byte) {
+	a.crypt(dst, src, a.cipher.EncryptBlock)
+}
+
+func (a *cipherBlockAdapter) Decrypt(dst, src []byte) {
+	a.crypt(dst, src, a.cipher.DecryptBlock)
+}
+
+func (a *cipherBlockAdapter) crypt(dst, src []byte, process func([]byte) ([]byte, error)) {
+	blockSize := a.cipher.BlockSize()
+	if len(src) < blockSize || len(dst) < blockSize {
+		panic("customlib: длина входных или выходных данных меньше блока")
+	}
+
+	output, err := process(src[:blockSize])
+	if err != nil {
+		panic("customlib: " + err.Error())
+	}
+	copy(dst, output)
+}
+
+type stdBlockCipher struct {
+	newBlock func(key []byte) (cipher.Block, error)
+	block    cipher.Block
+}
+
+func NewStdCipher(newBlock func(key []byte) (cipher.Block, error)) BlockCipher {
+	return &stdBlockCipher{newBlock: newBlock}
+}
+
+func (s *stdBlockCipher) NewBlockCipher() (BlockCipher, error) {
+	return &stdBlockCipher{newBlock: s.newBlock}, nil
+}
+
+func (s *stdBlockCipher) BlockSize() int {
+	if s.block == nil {
+		return 0
+	}
+	return s.block.BlockSize()
+}
+
+func (s *stdBlockCipher) SetKey(key []byte) error {
+	if s.newBlock == nil {
+		return errors.New("конструктор cipher.Block не задан")
+	}
+
+	block, err := s.newBlock(key)
+	if err != nil {
+		return err
+	}
+
+	s.block = block
+	return nil
+}
+
+func (s *stdBlockCipher) EncryptBlock(block []byte) ([]byte, error) {
+	return s.crypt(block, false)
+}
+
+func (s *stdBlockCipher) DecryptBlock(block []byte) ([]byte, error) {
+	return s.crypt(block, true)
+}
+
+func (s *stdBlockCipher) crypt(block []byte, decrypt bool) ([]byte, error) {
+	if s.block == nil {
+		return nil, errors.New("ключ не установлен")
+	}
+	if len(block) != s.block.BlockSize() {
+		return nil, errors.New("неверный размер блока")
+	}
+
+	output := make([]byte, len(block))
+	if decrypt {
+		s.block.Decrypt(output, block)
+	} else {
+		s.block.Encrypt(output, block)
+	}
+	return output, nil
+}
+
+type gcmAEAD struct {
+	ctx *CryptoContext
+}
+
+func NewAEAD(ctx *CryptoContext) (cipher.AEAD, error) {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	if ctx.mode != ModeGCM {
+		return nil, errors.New("интерфейс AEAD доступен только в режиме GCM")
+	}
+	if ctx.blockSize != gcmBlockSize {
+		return nil, errors.New("режим GCM требует блочный шифр с размером блока 128 бит")
+	}
+
+	return &gcmAEAD{ctx: ctx}, nil
+}
+
+func (g *gcmAEAD) NonceSize() int {
+	return ivSizeFor(ModeGCM, gcmBlockSize)
+}
+
+func (g *gcmAEAD) Overhead() int {
+	return gcmTagSize
+}
+
+func (g *gcmAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != g.NonceSize() {
+		panic("customlib: неверная длина nonce")
+	}
+
+	g.ctx.mu.RLock()
+	defer g.ctx.mu.RUnlock()
+
+	sealed, err := g.ctx.withIV(cloneBytes(nonce)).sealGCM(plaintext, additionalData)
+	if err != nil {
+		panic("customlib: " + err.Error())
+	}
+	return append(dst, sealed...)
+}
+
+func (g *gcmAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != g.NonceSize() {
+		return nil, errors.New("неверная длина nonce")
+	}
+
+	g.ctx.mu.RLock()
+	defer g.ctx.mu.RUnlock()
+
+	opened, err := g.ctx.withIV(cloneBytes(nonce)).openGCM(ciphertext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, opened...), nil
+}
@@ -0,0 +1,143 @@
+package customlib_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	stddes "crypto/des"
+	"errors"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/des"
+	"iSL1/rijndael"
+)
+
+func TestAsCipherBlockDESCBC(t *testing.T) {
+	key := mustHex(t, "0123456789abcdef")
+	iv := mustHex(t, "1234567890abcdef")
+	plaintext := testMessage(64)
+
+	ours, err := des.NewDES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ours.SetKey(key); err != nil {
+		t.Fatal(err)
+	}
+	reference, err := stddes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := customlib.AsCipherBlock(ours)
+	if block.BlockSize() != reference.BlockSize() {
+		t.Fatalf("размер блока %d, ожидался %d", block.BlockSize(), reference.BlockSize())
+	}
+
+	got := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(got, plaintext)
+	expected := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(reference, iv).CryptBlocks(expected, plaintext)
+	if !bytes.Equal(got, expected) {
+		t.Errorf("шифртекст %x, ожидался %x", got, expected)
+	}
+
+	decrypted := make([]byte, len(got))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, got)
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("расшифровано %x, ожидалось %x", decrypted, plaintext)
+	}
+}
+
+func TestNewStdCipher(t *testing.T) {
+	iv := testMessage(16)
+	plaintext := testMessage(64)
+
+	ctx, err := customlib.NewCryptoContextWithCipher(keyA, customlib.ModeCBC, customlib.PaddingPKCS7, iv, customlib.NewStdCipher(aes.NewCipher))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.SetKey(keyB); err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := ctx.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reference, err := aes.NewCipher(keyB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(reference, iv).CryptBlocks(expected, plaintext)
+	if !bytes.Equal(ciphertext[:len(expected)], expected) {
+		t.Errorf("шифртекст %x, ожидался %x", ciphertext[:len(expected)], expected)
+	}
+}
+
+func TestNewAEADMatchesStdGCM(t *testing.T) {
+	key := mustHex(t, gcmKey)
+	nonce := mustHex(t, "cafebabefacedbaddecaf888")
+	plaintext := mustHex(t, gcmPlaintext)
+	aad := mustHex(t, gcmAAD)
+
+	aesCipher, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := customlib.NewCryptoContextWithCipher(key, customlib.ModeGCM, customlib.PaddingZeros, nil, aesCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ours, err := customlib.NewAEAD(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ours.NonceSize() != reference.NonceSize() || ours.Overhead() != reference.Overhead() {
+		t.Fatalf("NonceSize/Overhead %d/%d, ожидалось %d/%d", ours.NonceSize(), ours.Overhead(), reference.NonceSize(), reference.Overhead())
+	}
+
+	prefix := []byte("prefix")
+	sealed := ours.Seal(append([]byte(nil), prefix...), nonce, plaintext, aad)
+	expected := reference.Seal(append([]byte(nil), prefix...), nonce, plaintext, aad)
+	if !bytes.Equal(sealed, expected) {
+		t.Errorf("шифртекст %x, ожидался %x", sealed, expected)
+	}
+
+	opened, err := ours.Open(nil, nonce, expected[len(prefix):], aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("расшифровано %x, ожидалось %x", opened, plaintext)
+	}
+
+	tampered := append([]byte(nil), expected[len(prefix):]...)
+	tampered[0] ^= 0x01
+	if _, err := ours.Open(nil, nonce, tampered, aad); !errors.Is(err, customlib.ErrAuthentication) {
+		t.Errorf("ожидалась ErrAuthentication, получено %v", err)
+	}
+	if _, err := ours.Open(nil, nonce[:8], expected[len(prefix):], aad); err == nil {
+		t.Error("ожидалась ошибка для nonce неверной длины")
+	}
+}
+
+func TestNewAEADRequiresGCM(t *testing.T) {
+	ctx := newAESContext(t, keyA, customlib.ModeCBC, make([]byte, 16))
+	if _, err := customlib.NewAEAD(ctx); err == nil {
+		t.Error("ожидалась ошибка для режима CBC")
+	}
+}
@@ -0,0 +1,233 @@
+package customlib
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+const streamBufferBlocks = 1024
+
+type encryptWriter struct {
+	w       io.Writer
+	ctx     *CryptoContext
+	mode    blockMode
+	pending []byte
+	closed  bool
+	lock    *sync.RWMutex
+}
+
+func NewEncryptWriter(w io.Writer, ctx *CryptoContext) (io.WriteCloser, error) {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	writer, err := newEncryptWriter(w, ctx.snapshot())
+	if err != nil {
+		return nil, err
+	}
+
+	writer.lock = ctx.mu
+	return writer, nil
+}
+
+func newEncryptWriter(w io.Writer, ctx *CryptoContext) (*encryptWriter, error) {
+	if ctx.wholeMessageMode() {
+		return nil, errors.New("режим шифрования не поддерживает потоковую обработку")
+	}
+
+	if ctx.autoIV {
+		iv, err := ctx.freshIV()
+		if err != nil {
+			return nil, err
+		}
+		_, err = w.Write(iv)
+		if err != nil {
+			return nil, err
+		}
+		ctx = ctx.withIV(iv)
+	}
+
+	mode, err := ctx.newBlockMode(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{w: w, ctx: ctx, mode: mode}, nil
+}
+
+func (ew *encryptWriter) Write(p []byte) (int, error) {
+	if ew.closed {
+		return 0, errors.New("запись в закрытый поток")
+	}
+
+	blockSize := ew.ctx.blockSize
+	written := 0
+
+	for len(p) > 0 {
+		n := min(len(p), blockSize*streamBufferBlocks-len(ew.pending))
+		ew.pending = append(ew.pending, p[:n]...)
+		p = p[n:]
+
+		full := len(ew.pending) / blockSize * blockSize
+		if full > 0 {
+			err := ew.flush(ew.pending[:full])
+			if err != nil {
+				return written, err
+			}
+			ew.pending = append(ew.pending[:0], ew.pending[full:]...)
+		}
+
+		written += n
+	}
+
+	return written, nil
+}
+
+func (ew *encryptWriter) Close() error {
+	if ew.closed {
+		return nil
+	}
+	ew.closed = true
+
+	padded, err := ew.ctx.applyPadding(ew.pending)
+	if err != nil {
+		return err
+	}
+	ew.pending = nil
+
+	return ew.flush(padded)
+}
+
+func (ew *encryptWriter) flush(data []byte) error {
+	if ew.lock != nil {
+		ew.lock.RLock()
+	}
+	encrypted, err := ew.mode.cryptBlocks(data)
+	if ew.lock != nil {
+		ew.lock.RUnlock()
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = ew.w.Write(encrypted)
+	return err
+}
+
+type decryptReader struct {
+	r       io.Reader
+	ctx     *CryptoContext
+	mode    blockMode
+	buf     []byte
+	pending []byte
+	held    []byte
+	out     []byte
+	eof     bool
+	err     error
+	lock    *sync.RWMutex
+}
+
+func NewDecryptReader(r io.Reader, ctx *CryptoContext) (io.Reader, error) {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	reader, err := newDecryptReader(r, ctx.snapshot())
+	if err != nil {
+		return nil, err
+	}
+
+	reader.lock = ctx.mu
+	return reader, nil
+}
+
+func newDecryptReader(r io.Reader, ctx *CryptoContext) (*decryptReader, error) {
+	if ctx.wholeMessageMode() {
+		return nil, errors.New("режим шифрования не поддерживает потоковую обработку")
+	}
+
+	if ctx.autoIV {
+		iv := make([]byte, ctx.ivSize())
+		_, err := io.ReadFull(r, iv)
+		if err != nil {
+			return nil, errors.New("данные короче вектора инициализации")
+		}
+		ctx = ctx.withIV(iv)
+	}
+
+	mode, err := ctx.newBlockMode(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{
+		r:    r,
+		ctx:  ctx,
+		mode: mode,
+		buf:  make([]byte, ctx.blockSize*streamBufferBlocks),
+	}, nil
+}
+
+func (dr *decryptReader) Read(p []byte) (int, error) {
+	for len(dr.out) == 0 {
+		if dr.err != nil {
+			return 0, dr.err
+		}
+		if dr.eof {
+			return 0, io.EOF
+		}
+		dr.err = dr.fill()
+	}
+
+	n := copy(p, dr.out)
+	dr.out = dr.out[n:]
+	return n, nil
+}
+
+func (dr *decryptReader) fill() error {
+	blockSize := dr.ctx.blockSize
+
+	n, err := dr.r.Read(dr.buf)
+	dr.pending = append(dr.pending, dr.buf[:n]...)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	full := len(dr.pending) / blockSize * blockSize
+	if full > 0 {
+		if dr.lock != nil {
+			dr.lock.RLock()
+		}
+		decrypted, decryptErr := dr.mode.cryptBlocks(dr.pending[:full])
+		if dr.lock != nil {
+			dr.lock.RUnlock()
+		}
+		if decryptErr != nil {
+			return decryptErr
+		}
+		dr.pending = append(dr.pending[:0], dr.pending[full:]...)
+
+		combined := append(dr.held, decrypted...)
+		if len(combined) >= blockSize {
+			dr.out = append(dr.out, combined[:len(combined)-blockSize]...)
+			dr.held = append([]byte(nil), combined[len(combined)-blockSize:]...)
+		} else {
+			dr.held = combined
+		}
+	}
+
+	if errors.Is(err, io.EOF) {
+		dr.eof = true
+		if len(dr.pending) != 0 {
+			return errors.New("данные не кратны размеру блока")
+		}
+
+		unpadded, unpadErr := dr.ctx.removePadding(dr.held)
+		if unpadErr != nil {
+			return unpadErr
+		}
+		dr.held = nil
+		dr.out = append(dr.out, unpadded...)
+	}
+
+	return nil
+}
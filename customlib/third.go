@@ -31,6 +31,14 @@ func NewFeistelCipher(keyExpander KeyExpander, cipherFunc CipherTransformation,
 	}, nil
 }
 
+func (fc *FeistelCipher) NewBlockCipher() (BlockCipher, error) {
+	return NewFeistelCipher(fc.keyExpander, fc.cipherFunc, fc.numRounds, fc.blockSize)
+}
+
+func (fc *FeistelCipher) BlockSize() int {
+	return fc.blockSize
+}
+
 func (fc *FeistelCipher) SetKey(key []byte) error {
 	roundKeys, err := fc.keyExpander.ExpandKey(key)
 	if err != nil {
@@ -48,25 +56,7 @@ func (fc *FeistelCipher) EncryptBlock(block []byte) ([]byte, error) {
 		return nil, errors.New("неверный размер блока")
 	}
 
-	left := make([]byte, fc.blockSize/2)
-	right := make([]byte, fc.blockSize/2)
-	copy(left, block[:fc.blockSize/2])
-	copy(right, block[fc.blockSize/2:])
-
-	for i := 0; i < fc.numRounds; i++ {
-		fResult, err := fc.cipherFunc.EncryptBlock(right, fc.roundKeys[i])
-		if err != nil {
-			return nil, err
-		}
-
-		newRight := xorBytes(left, fResult)
-
-		left = right
-		right = newRight
-	}
-
-	result := append(left, right...)
-	return result, nil
+	return fc.process(block, false)
 }
 
 func (fc *FeistelCipher) DecryptBlock(block []byte) ([]byte, error) {
@@ -74,24 +64,37 @@ func (fc *FeistelCipher) DecryptBlock(block []byte) ([]byte, error) {
 		return nil, errors.New("неверный размер блока")
 	}
 
+	return fc.process(block, true)
+}
+
+func (fc *FeistelCipher) process(block []byte, reverse bool) ([]byte, error) {
+	if fc.roundKeys == nil {
+		return nil, errors.New("ключ не установлен")
+	}
+
 	left := make([]byte, fc.blockSize/2)
 	right := make([]byte, fc.blockSize/2)
 	copy(left, block[:fc.blockSize/2])
 	copy(right, block[fc.blockSize/2:])
 
-	for i := fc.numRounds - 1; i >= 0; i-- {
-		fResult, err := fc.cipherFunc.EncryptBlock(left, fc.roundKeys[i])
+	for i := 0; i < fc.numRounds; i++ {
+		roundKey := fc.roundKeys[i]
+		if reverse {
+			roundKey = fc.roundKeys[fc.numRounds-1-i]
+		}
+
+		fResult, err := fc.cipherFunc.EncryptBlock(right, roundKey)
 		if err != nil {
 			return nil, err
 		}
 
-		newLeft := xorBytes(right, fResult)
+		newRight := xorBytes(left, fResult)
 
-		right = left
-		left = newLeft
+		left = right
+		right = newRight
 	}
 
-	result := append(left, right...)
+	result := append(right, left...)
 	return result, nil
 }
 
@@ -0,0 +1,227 @@
+package customlib
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+)
+
+const xtsBlockSize = 16
+
+func NewXTSCryptoContext(key []byte, dataCipher BlockCipher, tweakCipher BlockCipher, sectorSize int) (*CryptoContext, error) {
+	if dataCipher == nil || tweakCipher == nil {
+		return nil, errors.New("блочный шифр не может быть nil")
+	}
+	if sameCipher(dataCipher, tweakCipher) {
+		return nil, errors.New("шифр данных и шифр твиков должны быть разными экземплярами")
+	}
+	if sectorSize < xtsBlockSize {
+		return nil, errors.New("размер сектора должен быть не меньше размера блока")
+	}
+
+	ctx := &CryptoContext{
+		mode:        ModeXTS,
+		cipher:      dataCipher,
+		tweakCipher: tweakCipher,
+		sectorSize:  sectorSize,
+		mu:          new(sync.RWMutex),
+	}
+
+	err := ctx.SetKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+func sameCipher(a, b BlockCipher) bool {
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.TypeOf(a).Comparable() && a == b
+}
+
+func (ctx *CryptoContext) EncryptSector(sectorNum uint64, data []byte) ([]byte, error) {
+	if ctx.mode != ModeXTS {
+		return nil, errors.New("шифрование секторов доступно только в режиме XTS")
+	}
+
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.xtsSector(sectorNum, data, false)
+}
+
+func (ctx *CryptoContext) DecryptSector(sectorNum uint64, data []byte) ([]byte, error) {
+	if ctx.mode != ModeXTS {
+		return nil, errors.New("шифрование секторов доступно только в режиме XTS")
+	}
+
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	return ctx.xtsSector(sectorNum, data, true)
+}
+
+func (ctx *CryptoContext) setXTSKey(key []byte) error {
+	if ctx.tweakCipher == nil {
+		return errors.New("для режима XTS необходим шифр для твиков")
+	}
+	if len(key) == 0 || len(key)%2 != 0 {
+		return errors.New("ключ XTS должен состоять из двух ключей одинаковой длины")
+	}
+
+	half := len(key) / 2
+	dataCipher, err := ctx.keyedCipher(ctx.cipher, key[:half])
+	if err != nil {
+		return err
+	}
+	tweakCipher, err := ctx.keyedCipher(ctx.tweakCipher, key[half:])
+	if err != nil {
+		return err
+	}
+
+	if dataCipher.BlockSize() != xtsBlockSize || tweakCipher.BlockSize() != xtsBlockSize {
+		return errors.New("режим XTS требует шифр с размером блока 16 байт")
+	}
+
+	ctx.cipher = dataCipher
+	ctx.tweakCipher = tweakCipher
+	ctx.key = key
+	ctx.blockSize = xtsBlockSize
+	return nil
+}
+
+func (ctx *CryptoContext) xtsSectors(data []byte, decrypt bool) ([]byte, error) {
+	if ctx.sectorSize == 0 {
+		return nil, errors.New("размер сектора не задан")
+	}
+
+	result := make([]byte, 0, len(data))
+	for sector, offset := uint64(0), 0; offset < len(data); sector, offset = sector+1, offset+ctx.sectorSize {
+		output, err := ctx.xtsSector(sector, data[offset:min(offset+ctx.sectorSize, len(data))], decrypt)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, output...)
+	}
+
+	return result, nil
+}
+
+func (ctx *CryptoContext) processSectors(runCtx context.Context, r io.Reader, w io.Writer, decrypt bool) error {
+	if ctx.sectorSize == 0 {
+		return errors.New("размер сектора не задан")
+	}
+
+	buffer := make([]byte, ctx.sectorSize)
+	for sector := uint64(0); ; sector++ {
+		if err := runCtx.Err(); err != nil {
+			return err
+		}
+
+		n, err := io.ReadFull(r, buffer)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+
+		output, err := ctx.xtsSector(sector, buffer[:n], decrypt)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(output); err != nil {
+			return err
+		}
+
+		if n < ctx.sectorSize {
+			return nil
+		}
+	}
+}
+
+func (ctx *CryptoContext) xtsSector(sectorNum uint64, data []byte, decrypt bool) ([]byte, error) {
+	if len(data) < xtsBlockSize {
+		return nil, errors.New("данные сектора короче одного блока")
+	}
+
+	tweakInput := make([]byte, xtsBlockSize)
+	binary.LittleEndian.PutUint64(tweakInput, sectorNum)
+
+	tweak, err := ctx.tweakCipher.EncryptBlock(tweakInput)
+	if err != nil {
+		return nil, err
+	}
+
+	process := ctx.encryptBlock
+	if decrypt {
+		process = ctx.decryptBlock
+	}
+
+	fullBlocks := len(data) / xtsBlockSize
+	remainder := len(data) % xtsBlockSize
+	if remainder != 0 {
+		fullBlocks--
+	}
+
+	result := make([]byte, len(data))
+	for i := 0; i < fullBlocks; i++ {
+		start := i * xtsBlockSize
+		output, err := xexBlock(data[start:start+xtsBlockSize], tweak, process)
+		if err != nil {
+			return nil, err
+		}
+		copy(result[start:], output)
+		mulAlpha(tweak)
+	}
+
+	if remainder == 0 {
+		return result, nil
+	}
+
+	start := fullBlocks * xtsBlockSize
+	lastTweak := cloneBytes(tweak)
+	mulAlpha(lastTweak)
+
+	firstTweak, secondTweak := tweak, lastTweak
+	if decrypt {
+		firstTweak, secondTweak = lastTweak, tweak
+	}
+
+	stolen, err := xexBlock(data[start:start+xtsBlockSize], firstTweak, process)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make([]byte, xtsBlockSize)
+	copy(merged, data[start+xtsBlockSize:])
+	copy(merged[remainder:], stolen[remainder:])
+
+	output, err := xexBlock(merged, secondTweak, process)
+	if err != nil {
+		return nil, err
+	}
+
+	copy(result[start:], output)
+	copy(result[start+xtsBlockSize:], stolen[:remainder])
+	return result, nil
+}
+
+func xexBlock(block []byte, tweak []byte, process func([]byte) ([]byte, error)) ([]byte, error) {
+	output, err := process(xorBlocks(block, tweak))
+	if err != nil {
+		return nil, err
+	}
+	return xorBlocks(output, tweak), nil
+}
+
+func mulAlpha(tweak []byte) {
+	carry := tweak[len(tweak)-1] >> 7
+	for i := len(tweak) - 1; i > 0; i-- {
+		tweak[i] = tweak[i]<<1 | tweak[i-1]>>7
+	}
+	tweak[0] = tweak[0]<<1 ^ carry*0x87
+}
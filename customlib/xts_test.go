@@ -0,0 +1,173 @@
+package customlib_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"iSL1/customlib"
+	"iSL1/rijndael"
+)
+
+func newXTSContext(t *testing.T, key []byte, sectorSize int) *customlib.CryptoContext {
+	t.Helper()
+	dataCipher, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tweakCipher, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := customlib.NewXTSCryptoContext(key, dataCipher, tweakCipher, sectorSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func TestXTSVectors(t *testing.T) {
+	tests := []struct {
+		name                  string
+		key                   string
+		sector                uint64
+		plaintext, ciphertext string
+	}{
+		{
+			name:       "vector1",
+			key:        "0000000000000000000000000000000000000000000000000000000000000000",
+			sector:     0,
+			plaintext:  "0000000000000000000000000000000000000000000000000000000000000000",
+			ciphertext: "917cf69ebd68b2ec9b9fe9a3eadda692cd43d2f59598ed858c02c2652fbf922e",
+		},
+		{
+			name:       "vector15",
+			key:        "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0",
+			sector:     0x123456789a,
+			plaintext:  "000102030405060708090a0b0c0d0e0f10",
+			ciphertext: "6c1625db4671522d3d7599601de7ca09ed",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			plaintext := mustHex(t, tc.plaintext)
+			expected := mustHex(t, tc.ciphertext)
+			ctx := newXTSContext(t, mustHex(t, tc.key), len(plaintext))
+
+			ciphertext, err := ctx.EncryptSector(tc.sector, plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(ciphertext, expected) {
+				t.Errorf("шифртекст %x, ожидался %x", ciphertext, expected)
+			}
+
+			decrypted, err := ctx.DecryptSector(tc.sector, expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("расшифровано %x, ожидалось %x", decrypted, plaintext)
+			}
+		})
+	}
+}
+
+func TestXTSSectorTweak(t *testing.T) {
+	ctx := newXTSContext(t, testMessage(32), 32)
+	plaintext := testMessage(32)
+
+	first, err := ctx.EncryptSector(1, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ctx.EncryptSector(2, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first, second) {
+		t.Error("разные секторы дали одинаковый шифртекст")
+	}
+
+	if _, err := ctx.EncryptSector(0, testMessage(15)); err == nil {
+		t.Error("ожидалась ошибка для данных короче блока")
+	}
+}
+
+func TestXTSContainerMatchesSectors(t *testing.T) {
+	ctx := newXTSContext(t, testMessage(32), 512)
+	plaintext := testMessage(512*3 + 100)
+
+	sealed := encryptContainer(t, ctx, plaintext)
+	expected, err := ctx.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(sealed, expected) {
+		t.Error("контейнер XTS не совпадает с посекторным шифрованием")
+	}
+
+	decrypted, err := decryptContainer(ctx, sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Error("расшифрованные данные не совпадают")
+	}
+}
+
+type notifyWriter struct {
+	mu      sync.Mutex
+	written int
+	notify  chan struct{}
+}
+
+func (w *notifyWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	w.written += len(p)
+	w.mu.Unlock()
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+func (w *notifyWriter) total() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written
+}
+
+func TestXTSContainerStreamsSectors(t *testing.T) {
+	ctx := newXTSContext(t, testMessage(32), 512)
+	pr, pw := io.Pipe()
+	out := &notifyWriter{notify: make(chan struct{}, 1)}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ctx.EncryptStream(context.Background(), pr, out)
+	}()
+
+	if _, err := pw.Write(testMessage(1024)); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for out.total() < 1024 {
+		select {
+		case <-out.notify:
+		case <-deadline:
+			t.Fatal("секторы не записаны до окончания входных данных")
+		}
+	}
+
+	pw.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,180 @@
+package deal
+
+import (
+	"errors"
+	"iSL1/customlib"
+	"iSL1/des"
+	"sync"
+)
+
+var keyScheduleKey = []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF}
+
+type DEALKeyExpander struct{}
+
+func (ke *DEALKeyExpander) ExpandKey(key []byte) ([][]byte, error) {
+	numRounds, err := roundsForKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleCipher, err := des.NewDES()
+	if err != nil {
+		return nil, err
+	}
+	err = scheduleCipher.SetKey(keyScheduleKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBlocks := len(key) / 8
+	roundKeys := make([][]byte, numRounds)
+	prev := make([]byte, 8)
+
+	for i := 0; i < numRounds; i++ {
+		block := make([]byte, 8)
+		copy(block, key[(i%keyBlocks)*8:(i%keyBlocks+1)*8])
+
+		if i > 0 {
+			for j := range block {
+				block[j] ^= prev[j]
+			}
+		}
+
+		if i >= keyBlocks {
+			constant := uint64(1) << (i - keyBlocks)
+			for j := 0; j < 8; j++ {
+				block[7-j] ^= byte(constant >> (8 * j))
+			}
+		}
+
+		roundKey, err := scheduleCipher.EncryptBlock(block)
+		if err != nil {
+			return nil, err
+		}
+
+		roundKeys[i] = roundKey
+		prev = roundKey
+	}
+
+	return roundKeys, nil
+}
+
+type DEALCipherTransformation struct {
+	mu      sync.Mutex
+	ciphers map[string]*des.DES
+}
+
+func (ct *DEALCipherTransformation) EncryptBlock(inputBlock []byte, roundKey []byte) ([]byte, error) {
+	roundCipher, err := ct.cipherFor(roundKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return roundCipher.EncryptBlock(inputBlock)
+}
+
+func (ct *DEALCipherTransformation) DecryptBlock(inputBlock []byte, roundKey []byte) ([]byte, error) {
+	roundCipher, err := ct.cipherFor(roundKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return roundCipher.DecryptBlock(inputBlock)
+}
+
+func (ct *DEALCipherTransformation) cipherFor(roundKey []byte) (*des.DES, error) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	if roundCipher, ok := ct.ciphers[string(roundKey)]; ok {
+		return roundCipher, nil
+	}
+
+	roundCipher, err := des.NewDES()
+	if err != nil {
+		return nil, err
+	}
+	err = roundCipher.SetKey(roundKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if ct.ciphers == nil {
+		ct.ciphers = make(map[string]*des.DES)
+	}
+	ct.ciphers[string(roundKey)] = roundCipher
+
+	return roundCipher, nil
+}
+
+func roundsForKey(key []byte) (int, error) {
+	switch len(key) {
+	case 16, 24:
+		return 6, nil
+	case 32:
+		return 8, nil
+	default:
+		return 0, errors.New("ключ должен быть длиной 16, 24 или 32 байта")
+	}
+}
+
+var (
+	_ customlib.BlockCipher        = (*DEAL)(nil)
+	_ customlib.BlockCipherFactory = (*DEAL)(nil)
+)
+
+type DEAL struct {
+	feistelCipher *customlib.FeistelCipher
+}
+
+func NewDEAL() (*DEAL, error) {
+	return &DEAL{}, nil
+}
+
+func (deal *DEAL) NewBlockCipher() (customlib.BlockCipher, error) {
+	return NewDEAL()
+}
+
+func (deal *DEAL) BlockSize() int {
+	return 16
+}
+
+func (deal *DEAL) AlgorithmID() customlib.AlgorithmID {
+	return customlib.AlgorithmDEAL
+}
+
+func (deal *DEAL) SetKey(key []byte) error {
+	numRounds, err := roundsForKey(key)
+	if err != nil {
+		return err
+	}
+
+	feistelCipher, err := customlib.NewFeistelCipher(&DEALKeyExpander{}, &DEALCipherTransformation{}, numRounds, 16)
+	if err != nil {
+		return err
+	}
+
+	err = feistelCipher.SetKey(key)
+	if err != nil {
+		return err
+	}
+
+	deal.feistelCipher = feistelCipher
+	return nil
+}
+
+func (deal *DEAL) EncryptBlock(block []byte) ([]byte, error) {
+	if deal.feistelCipher == nil {
+		return nil, errors.New("ключ не установлен")
+	}
+
+	return deal.feistelCipher.EncryptBlock(block)
+}
+
+func (deal *DEAL) DecryptBlock(block []byte) ([]byte, error) {
+	if deal.feistelCipher == nil {
+		return nil, errors.New("ключ не установлен")
+	}
+
+	return deal.feistelCipher.DecryptBlock(block)
+}
@@ -0,0 +1,136 @@
+package deal_test
+
+import (
+	"bytes"
+	stddes "crypto/des"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/deal"
+)
+
+func testKey(n int) []byte {
+	key := make([]byte, n)
+	for i := range key {
+		key[i] = byte(i*13 + 5)
+	}
+	return key
+}
+
+func referenceEncrypt(t *testing.T, roundKeys [][]byte, block []byte) []byte {
+	t.Helper()
+	left := append([]byte(nil), block[:8]...)
+	right := append([]byte(nil), block[8:]...)
+
+	for _, roundKey := range roundKeys {
+		des, err := stddes.NewCipher(roundKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := make([]byte, 8)
+		des.Encrypt(f, right)
+		for i := range f {
+			f[i] ^= left[i]
+		}
+		left, right = right, f
+	}
+
+	return append(right, left...)
+}
+
+func TestDEALRoundTrip(t *testing.T) {
+	tests := []struct {
+		keySize int
+		rounds  int
+	}{
+		{16, 6},
+		{24, 6},
+		{32, 8},
+	}
+
+	block := []byte("0123456789abcdef")
+	for _, tc := range tests {
+		key := testKey(tc.keySize)
+		roundKeys, err := (&deal.DEALKeyExpander{}).ExpandKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(roundKeys) != tc.rounds {
+			t.Fatalf("ключ %d байт: %d раундов, ожидалось %d", tc.keySize, len(roundKeys), tc.rounds)
+		}
+
+		cipher, err := deal.NewDEAL()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cipher.SetKey(key); err != nil {
+			t.Fatal(err)
+		}
+
+		ciphertext, err := cipher.EncryptBlock(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := referenceEncrypt(t, roundKeys, block); !bytes.Equal(ciphertext, expected) {
+			t.Errorf("ключ %d байт: шифртекст %x, ожидался %x", tc.keySize, ciphertext, expected)
+		}
+
+		decrypted, err := cipher.DecryptBlock(ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decrypted, block) {
+			t.Errorf("ключ %d байт: расшифровано %x, ожидалось %x", tc.keySize, decrypted, block)
+		}
+	}
+}
+
+func TestDEALCryptoContext(t *testing.T) {
+	plaintext := bytes.Repeat([]byte("DEAL через CryptoContext "), 20)
+
+	for _, keySize := range []int{16, 24, 32} {
+		cipher, err := deal.NewDEAL()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, err := customlib.NewCryptoContextWithCipher(testKey(keySize), customlib.ModeCBC, customlib.PaddingPKCS7, make([]byte, 16), cipher)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ciphertext, err := ctx.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decrypted, err := ctx.Decrypt(ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("ключ %d байт: расшифровка не совпала", keySize)
+		}
+	}
+}
+
+func TestDEALRejectsKeySize(t *testing.T) {
+	for _, keySize := range []int{0, 8, 15, 20, 40} {
+		cipher, err := deal.NewDEAL()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cipher.SetKey(testKey(keySize)); err == nil {
+			t.Errorf("ключ %d байт: ожидалась ошибка", keySize)
+		}
+		if _, err := customlib.NewCryptoContextWithCipher(testKey(keySize), customlib.ModeCBC, customlib.PaddingPKCS7, make([]byte, 16), cipher); err == nil {
+			t.Errorf("ключ %d байт: ожидалась ошибка контекста", keySize)
+		}
+	}
+
+	cipher, err := deal.NewDEAL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cipher.EncryptBlock(make([]byte, 16)); err == nil {
+		t.Error("ожидалась ошибка шифрования без ключа")
+	}
+}
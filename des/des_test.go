@@ -0,0 +1,91 @@
+package des_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/des"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestDESBlockVectors(t *testing.T) {
+	tests := []struct {
+		key, plaintext, ciphertext string
+	}{
+		{"133457799bbcdff1", "0123456789abcdef", "85e813540f0ab405"},
+		{"0123456789abcdef", "4e6f772069732074", "3fa40e8a984d4815"},
+		{"0000000000000000", "0000000000000000", "8ca64de9c1b123a7"},
+		{"ffffffffffffffff", "ffffffffffffffff", "7359b2163e4edc58"},
+		{"3000000000000000", "1000000000000001", "958e6e627a05557b"},
+		{"0123456789abcdef", "1111111111111111", "17668dfc7292532d"},
+	}
+
+	for _, tc := range tests {
+		cipher, err := des.NewDES()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cipher.SetKey(mustHex(t, tc.key)); err != nil {
+			t.Fatal(err)
+		}
+
+		ciphertext, err := cipher.EncryptBlock(mustHex(t, tc.plaintext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ciphertext, mustHex(t, tc.ciphertext)) {
+			t.Errorf("ключ %s: шифртекст %x, ожидался %s", tc.key, ciphertext, tc.ciphertext)
+		}
+
+		plaintext, err := cipher.DecryptBlock(ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(plaintext, mustHex(t, tc.plaintext)) {
+			t.Errorf("ключ %s: расшифровано %x, ожидалось %s", tc.key, plaintext, tc.plaintext)
+		}
+	}
+}
+
+func TestDESCBCVector(t *testing.T) {
+	plaintext := mustHex(t, "4e6f77206973207468652074696d6520666f7220616c6c20")
+	expected := mustHex(t, "e5c7cdde872bf27c43e934008c389c0f683788499a7c05f6")
+
+	cipher, err := des.NewDES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := customlib.NewCryptoContextWithCipher(mustHex(t, "0123456789abcdef"), customlib.ModeCBC, customlib.PaddingPKCS7, mustHex(t, "1234567890abcdef"), cipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.BlockSize() != 8 {
+		t.Fatalf("размер блока %d, ожидался 8", ctx.BlockSize())
+	}
+
+	ciphertext, err := ctx.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ciphertext[:len(expected)], expected) {
+		t.Errorf("шифртекст %x, ожидался %x", ciphertext[:len(expected)], expected)
+	}
+
+	decrypted, err := ctx.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("расшифровано %x, ожидалось %x", decrypted, plaintext)
+	}
+}
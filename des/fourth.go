@@ -129,8 +129,7 @@ var (
 	}
 )
 
-func leftShift(bits []byte, shift int) []byte {
-	bitLen := len(bits) * 8
+func leftShift(bits []byte, shift int, bitLen int) []byte {
 	totalShifts := shift % bitLen
 	result := make([]byte, len(bits))
 	for i := 0; i < bitLen; i++ {
@@ -180,15 +179,19 @@ func (ke *DESKeyExpander) ExpandKey(key []byte) ([][]byte, error) {
 
 	key56 := permuteBits(key, pc1)
 
-	c := key56[:len(key56)/2]
-	d := key56[len(key56)/2:]
+	c := getBits(key56, 0, 28)
+	d := getBits(key56, 28, 28)
 
 	roundKeys := make([][]byte, 16)
 	for i := 0; i < 16; i++ {
-		c = leftShift(c, keyShifts[i])
-		d = leftShift(d, keyShifts[i])
-
-		cd := append(c, d...)
+		c = leftShift(c, keyShifts[i], 28)
+		d = leftShift(d, keyShifts[i], 28)
+
+		cd := make([]byte, 7)
+		for j := 0; j < 28; j++ {
+			setBit(cd, j, getBit(c, j))
+			setBit(cd, 28+j, getBit(d, j))
+		}
 		roundKey := permuteBits(cd, pc2)
 		roundKeys[i] = roundKey
 	}
@@ -249,6 +252,11 @@ func setBits(data []byte, offset int, length int, value byte) {
 	}
 }
 
+var (
+	_ customlib.BlockCipher        = (*DES)(nil)
+	_ customlib.BlockCipherFactory = (*DES)(nil)
+)
+
 type DES struct {
 	feistelCipher *customlib.FeistelCipher
 }
@@ -269,6 +277,18 @@ func NewDES() (*DES, error) {
 	}, nil
 }
 
+func (des *DES) NewBlockCipher() (customlib.BlockCipher, error) {
+	return NewDES()
+}
+
+func (des *DES) BlockSize() int {
+	return 8
+}
+
+func (des *DES) AlgorithmID() customlib.AlgorithmID {
+	return customlib.AlgorithmDES
+}
+
 func (des *DES) SetKey(key []byte) error {
 	return des.feistelCipher.SetKey(key)
 }
@@ -2,38 +2,9 @@ package main
 
 import (
 	"fmt"
+	"iSL1/customlib"
 )
 
-func GetBit(value []byte, bitIndex int, indexFromLSB bool) int {
-	byteIndex := bitIndex / 8
-	bitOffset := bitIndex % 8
-
-	if indexFromLSB {
-		return int((value[byteIndex] >> bitOffset) & 1)
-	} else {
-		return int((value[byteIndex] >> (7 - bitOffset)) & 1)
-	}
-}
-
-func SetBit(value []byte, bitIndex int, bit int, indexFromLSB bool) {
-	byteIndex := bitIndex / 8
-	bitOffset := bitIndex % 8
-
-	if indexFromLSB {
-		if bit == 1 {
-			value[byteIndex] |= (1 << bitOffset)
-		} else {
-			value[byteIndex] &^= (1 << bitOffset)
-		}
-	} else {
-		if bit == 1 {
-			value[byteIndex] |= (1 << (7 - bitOffset))
-		} else {
-			value[byteIndex] &^= (1 << (7 - bitOffset))
-		}
-	}
-}
-
 func PermuteBits(value []byte, pBlock []int, indexFromLSB bool, startBitNumber int) []byte {
 	outputBitsLen := len(pBlock)
 	outputBytesLen := (outputBitsLen + 7) / 8
@@ -42,9 +13,9 @@ func PermuteBits(value []byte, pBlock []int, indexFromLSB bool, startBitNumber i
 	for i, p := range pBlock {
 		inputBitIndex := p - startBitNumber
 
-		bit := GetBit(value, inputBitIndex, indexFromLSB)
+		bit := customlib.GetBit(value, inputBitIndex, indexFromLSB)
 
-		SetBit(output, i, bit, indexFromLSB)
+		customlib.SetBit(output, i, bit, indexFromLSB)
 	}
 
 	return output
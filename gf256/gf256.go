@@ -0,0 +1,298 @@
+package gf256
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+type InvalidModulusError struct {
+	Modulus uint16
+}
+
+func (e *InvalidModulusError) Error() string {
+	return fmt.Sprintf("многочлен %#x не является многочленом степени 8", e.Modulus)
+}
+
+type ReducibleModulusError struct {
+	Modulus uint16
+}
+
+func (e *ReducibleModulusError) Error() string {
+	return fmt.Sprintf("многочлен %#x приводим над GF(2)", e.Modulus)
+}
+
+type ZeroInverseError struct{}
+
+func (e *ZeroInverseError) Error() string {
+	return "у нулевого элемента нет обратного"
+}
+
+type ZeroPolynomialError struct{}
+
+func (e *ZeroPolynomialError) Error() string {
+	return "нулевой многочлен не раскладывается на множители"
+}
+
+var irreducible [256]bool
+
+func init() {
+	for low := 0; low < 256; low++ {
+		irreducible[low] = isIrreducible(uint64(0x100 | low))
+	}
+}
+
+func Add(a, b byte) byte {
+	return a ^ b
+}
+
+func Mul(a, b byte, modulus uint16) (byte, error) {
+	err := checkModulus(modulus)
+	if err != nil {
+		return 0, err
+	}
+
+	return mul(a, b, byte(modulus)), nil
+}
+
+func Inverse(a byte, modulus uint16) (byte, error) {
+	err := checkModulus(modulus)
+	if err != nil {
+		return 0, err
+	}
+	if a == 0 {
+		return 0, &ZeroInverseError{}
+	}
+
+	result := byte(1)
+	base := a
+	for exp := 254; exp > 0; exp >>= 1 {
+		if exp&1 != 0 {
+			result = mul(result, base, byte(modulus))
+		}
+		base = mul(base, base, byte(modulus))
+	}
+
+	return result, nil
+}
+
+func IsIrreducible(poly uint16) (bool, error) {
+	if poly < 0x100 || poly > 0x1FF {
+		return false, &InvalidModulusError{Modulus: poly}
+	}
+
+	return irreducible[poly&0xFF], nil
+}
+
+func IrreduciblePolynomials() []uint16 {
+	var result []uint16
+	for low := 0; low < 256; low++ {
+		if irreducible[low] {
+			result = append(result, uint16(0x100|low))
+		}
+	}
+	return result
+}
+
+func Factorize(poly uint64) ([]uint64, error) {
+	if poly == 0 {
+		return nil, &ZeroPolynomialError{}
+	}
+
+	var factors []uint64
+	for _, part := range squareFreeFactors(poly, 1) {
+		for _, group := range distinctDegreeFactors(part.poly) {
+			for _, factor := range equalDegreeFactors(group.poly, group.degree) {
+				for i := 0; i < part.multiplicity; i++ {
+					factors = append(factors, factor)
+				}
+			}
+		}
+	}
+
+	sort.Slice(factors, func(i, j int) bool { return factors[i] < factors[j] })
+	return factors, nil
+}
+
+func checkModulus(modulus uint16) error {
+	ok, err := IsIrreducible(modulus)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &ReducibleModulusError{Modulus: modulus}
+	}
+	return nil
+}
+
+func mul(a, b byte, reduction byte) byte {
+	var result byte
+	for b != 0 {
+		if b&1 != 0 {
+			result ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= reduction
+		}
+		b >>= 1
+	}
+	return result
+}
+
+func degree(p uint64) int {
+	return 63 - bits.LeadingZeros64(p)
+}
+
+func polyDivMod(a, b uint64) (uint64, uint64) {
+	var quotient uint64
+	db := degree(b)
+	for a != 0 && degree(a) >= db {
+		shift := degree(a) - db
+		quotient |= 1 << shift
+		a ^= b << shift
+	}
+	return quotient, a
+}
+
+func polyGCD(a, b uint64) uint64 {
+	for b != 0 {
+		_, r := polyDivMod(a, b)
+		a, b = b, r
+	}
+	return a
+}
+
+func mulMod(a, b, m uint64) uint64 {
+	var hi, lo uint64
+	for i := 0; i < 64; i++ {
+		if b&(1<<i) != 0 {
+			lo ^= a << i
+			if i > 0 {
+				hi ^= a >> (64 - i)
+			}
+		}
+	}
+
+	dm := degree(m)
+	for i := 127; i >= dm; i-- {
+		var set bool
+		if i >= 64 {
+			set = hi&(1<<(i-64)) != 0
+		} else {
+			set = lo&(1<<i) != 0
+		}
+		if !set {
+			continue
+		}
+
+		shift := i - dm
+		if shift >= 64 {
+			hi ^= m << (shift - 64)
+			continue
+		}
+		lo ^= m << shift
+		if shift > 0 {
+			hi ^= m >> (64 - shift)
+		}
+	}
+	return lo
+}
+
+func isIrreducible(p uint64) bool {
+	if degree(p) < 1 {
+		return false
+	}
+	factors, _ := Factorize(p)
+	return len(factors) == 1
+}
+
+type polyPart struct {
+	poly         uint64
+	multiplicity int
+	degree       int
+}
+
+func derivative(p uint64) uint64 {
+	return (p >> 1) & 0x5555555555555555
+}
+
+func sqrtPoly(p uint64) uint64 {
+	var result uint64
+	for i := 0; i < 32; i++ {
+		if p&(1<<(2*i)) != 0 {
+			result |= 1 << i
+		}
+	}
+	return result
+}
+
+func squareFreeFactors(f uint64, multiplicity int) []polyPart {
+	if degree(f) < 1 {
+		return nil
+	}
+
+	var parts []polyPart
+	c := polyGCD(f, derivative(f))
+	w, _ := polyDivMod(f, c)
+
+	for i := 1; w != 1; i++ {
+		y := polyGCD(w, c)
+		factor, _ := polyDivMod(w, y)
+		if factor != 1 {
+			parts = append(parts, polyPart{poly: factor, multiplicity: i * multiplicity})
+		}
+		w = y
+		c, _ = polyDivMod(c, y)
+	}
+
+	if c != 1 {
+		parts = append(parts, squareFreeFactors(sqrtPoly(c), 2*multiplicity)...)
+	}
+
+	return parts
+}
+
+func distinctDegreeFactors(f uint64) []polyPart {
+	var groups []polyPart
+	h := uint64(2)
+
+	for d := 1; degree(f) >= 2*d; d++ {
+		h = mulMod(h, h, f)
+		g := polyGCD(f, h^2)
+		if g != 1 {
+			groups = append(groups, polyPart{poly: g, degree: d})
+			f, _ = polyDivMod(f, g)
+			_, h = polyDivMod(h, f)
+		}
+	}
+
+	if degree(f) > 0 {
+		groups = append(groups, polyPart{poly: f, degree: degree(f)})
+	}
+
+	return groups
+}
+
+func equalDegreeFactors(f uint64, d int) []uint64 {
+	if degree(f) == d {
+		return []uint64{f}
+	}
+
+	for a := uint64(2); ; a++ {
+		_, a0 := polyDivMod(a, f)
+		trace := a0
+		term := a0
+		for j := 1; j < d; j++ {
+			term = mulMod(term, term, f)
+			trace ^= term
+		}
+
+		g := polyGCD(f, trace)
+		if g != 1 && g != f {
+			rest, _ := polyDivMod(f, g)
+			return append(equalDegreeFactors(g, d), equalDegreeFactors(rest, d)...)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package gf256_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"iSL1/gf256"
+)
+
+const aesModulus = 0x11B
+
+func clmul(a, b uint64) uint64 {
+	var result uint64
+	for ; b != 0; b >>= 1 {
+		if b&1 != 0 {
+			result ^= a
+		}
+		a <<= 1
+	}
+	return result
+}
+
+func TestIrreduciblePolynomials(t *testing.T) {
+	polys := gf256.IrreduciblePolynomials()
+	if len(polys) != 30 {
+		t.Fatalf("найдено %d неприводимых многочленов, ожидалось 30", len(polys))
+	}
+	for _, poly := range polys {
+		if poly == aesModulus {
+			return
+		}
+	}
+	t.Error("модуль AES 0x11B не найден среди неприводимых")
+}
+
+func TestMul(t *testing.T) {
+	product, err := gf256.Mul(0x57, 0x83, aesModulus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if product != 0xC1 {
+		t.Errorf("0x57*0x83 = %#x, ожидалось 0xc1", product)
+	}
+}
+
+func TestInverseRoundTrip(t *testing.T) {
+	inverse, err := gf256.Inverse(0x53, aesModulus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inverse != 0xCA {
+		t.Errorf("обратный к 0x53 = %#x, ожидалось 0xca", inverse)
+	}
+
+	for _, modulus := range gf256.IrreduciblePolynomials() {
+		for a := 1; a < 256; a++ {
+			inverse, err := gf256.Inverse(byte(a), modulus)
+			if err != nil {
+				t.Fatal(err)
+			}
+			product, err := gf256.Mul(byte(a), inverse, modulus)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if product != 1 {
+				t.Fatalf("модуль %#x: %#x * %#x = %#x", modulus, a, inverse, product)
+			}
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var reducible *gf256.ReducibleModulusError
+	if _, err := gf256.Mul(2, 3, 0x100); !errors.As(err, &reducible) || reducible.Modulus != 0x100 {
+		t.Errorf("ожидалась ReducibleModulusError, получено %v", err)
+	}
+
+	var zero *gf256.ZeroInverseError
+	if _, err := gf256.Inverse(0, aesModulus); !errors.As(err, &zero) {
+		t.Errorf("ожидалась ZeroInverseError, получено %v", err)
+	}
+
+	for _, modulus := range []uint16{0xFF, 0x200} {
+		var invalid *gf256.InvalidModulusError
+		if _, err := gf256.IsIrreducible(modulus); !errors.As(err, &invalid) || invalid.Modulus != modulus {
+			t.Errorf("%#x: ожидалась InvalidModulusError, получено %v", modulus, err)
+		}
+	}
+
+	var zeroPoly *gf256.ZeroPolynomialError
+	if _, err := gf256.Factorize(0); !errors.As(err, &zeroPoly) {
+		t.Errorf("ожидалась ZeroPolynomialError, получено %v", err)
+	}
+}
+
+func TestFactorize(t *testing.T) {
+	tests := []struct {
+		poly    uint64
+		factors []uint64
+	}{
+		{0x5, []uint64{0x3, 0x3}},
+		{0x100, []uint64{0x2, 0x2, 0x2, 0x2, 0x2, 0x2, 0x2, 0x2}},
+		{aesModulus, []uint64{aesModulus}},
+		{clmul(0x7, aesModulus), []uint64{0x7, aesModulus}},
+		{clmul(clmul(0x3, 0xB), clmul(0x11D, 0x11D)), []uint64{0x3, 0xB, 0x11D, 0x11D}},
+	}
+
+	for _, tc := range tests {
+		factors, err := gf256.Factorize(tc.poly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(factors, tc.factors) {
+			t.Errorf("%#x: множители %#x, ожидались %#x", tc.poly, factors, tc.factors)
+		}
+	}
+}
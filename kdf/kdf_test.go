@@ -0,0 +1,97 @@
+package kdf_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"testing"
+
+	"iSL1/kdf"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPBKDF2Vectors(t *testing.T) {
+	tests := []struct {
+		name       string
+		h          func() hash.Hash
+		password   string
+		salt       string
+		iterations int
+		expected   string
+	}{
+		{"rfc6070-1", sha1.New, "password", "salt", 1, "0c60c80f961f0e71f3a9b524af6012062fe037a6"},
+		{"rfc6070-2", sha1.New, "password", "salt", 2, "ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957"},
+		{"rfc6070-3", sha1.New, "password", "salt", 4096, "4b007901b765489abead49d926f721d065a429c1"},
+		{"rfc6070-5", sha1.New, "passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, "3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038"},
+		{"rfc6070-6", sha1.New, "pass\x00word", "sa\x00lt", 4096, "56fa6aa75548099dcc37d7f03425e0c3"},
+		{"rfc7914", sha256.New, "passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expected := mustHex(t, tc.expected)
+			key, err := kdf.PBKDF2([]byte(tc.password), []byte(tc.salt), tc.iterations, len(expected), tc.h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(key, expected) {
+				t.Errorf("ключ %x, ожидался %x", key, expected)
+			}
+		})
+	}
+}
+
+func TestScryptVectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		salt     string
+		n, r, p  int
+		expected string
+	}{
+		{"rfc7914-1", "", "", 16, 1, 1, "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906"},
+		{"rfc7914-2", "password", "NaCl", 1024, 8, 16, "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"},
+		{"rfc7914-3", "pleaseletmein", "SodiumChloride", 16384, 8, 1, "7023bdcb3afd7348461c06cd81fd38ebfda8fbba904f8e3ea9b543f6545da1f2d5432955613f0fcf62d49705242a9af9e61e85dc0d651e40dfcf017b45575887"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expected := mustHex(t, tc.expected)
+			key, err := kdf.Scrypt([]byte(tc.password), []byte(tc.salt), tc.n, tc.r, tc.p, len(expected))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(key, expected) {
+				t.Errorf("ключ %x, ожидался %x", key, expected)
+			}
+		})
+	}
+}
+
+func TestScryptInvalidParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		n, r, p int
+	}{
+		{"n not power of two", 1000, 8, 1},
+		{"n too small", 1, 8, 1},
+		{"zero r", 16, 0, 1},
+		{"zero p", 16, 8, 0},
+	}
+
+	for _, tc := range tests {
+		if _, err := kdf.Scrypt([]byte("password"), []byte("salt"), tc.n, tc.r, tc.p, 32); err == nil {
+			t.Errorf("%s: ожидалась ошибка", tc.name)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package kdf
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+func PBKDF2(password []byte, salt []byte, iterations int, keyLen int, h func() hash.Hash) ([]byte, error) {
+	if iterations <= 0 {
+		return nil, errors.New("число итераций должно быть положительным")
+	}
+	if keyLen <= 0 {
+		return nil, errors.New("длина ключа должна быть положительной")
+	}
+
+	prf := hmac.New(h, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	result := make([]byte, 0, numBlocks*hashLen)
+	counter := make([]byte, 4)
+	u := make([]byte, 0, hashLen)
+
+	for block := 1; block <= numBlocks; block++ {
+		binary.BigEndian.PutUint32(counter, uint32(block))
+
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter)
+		u = prf.Sum(u[:0])
+
+		t := make([]byte, hashLen)
+		copy(t, u)
+
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		result = append(result, t...)
+	}
+
+	return result[:keyLen], nil
+}
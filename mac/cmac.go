@@ -0,0 +1,99 @@
+package mac
+
+import (
+	"errors"
+	"iSL1/customlib"
+)
+
+type MAC interface {
+	Size() int
+	Sum(data []byte) ([]byte, error)
+}
+
+type CMAC struct {
+	cipher customlib.BlockCipher
+	k1     []byte
+	k2     []byte
+}
+
+func NewCMAC(cipher customlib.BlockCipher) (*CMAC, error) {
+	if cipher == nil {
+		return nil, errors.New("блочный шифр не может быть nil")
+	}
+
+	var rb byte
+	switch cipher.BlockSize() {
+	case 8:
+		rb = 0x1B
+	case 16:
+		rb = 0x87
+	default:
+		return nil, errors.New("CMAC поддерживает только блоки длиной 8 или 16 байт")
+	}
+
+	l, err := cipher.EncryptBlock(make([]byte, cipher.BlockSize()))
+	if err != nil {
+		return nil, err
+	}
+
+	k1 := shiftSubkey(l, rb)
+	k2 := shiftSubkey(k1, rb)
+
+	return &CMAC{cipher: cipher, k1: k1, k2: k2}, nil
+}
+
+func (c *CMAC) Size() int {
+	return c.cipher.BlockSize()
+}
+
+func (c *CMAC) Sum(data []byte) ([]byte, error) {
+	blockSize := c.cipher.BlockSize()
+
+	n := (len(data) + blockSize - 1) / blockSize
+	complete := n > 0 && len(data)%blockSize == 0
+	if n == 0 {
+		n = 1
+	}
+
+	last := make([]byte, blockSize)
+	copy(last, data[(n-1)*blockSize:])
+	if complete {
+		xorInto(last, c.k1)
+	} else {
+		last[len(data)-(n-1)*blockSize] = 0x80
+		xorInto(last, c.k2)
+	}
+
+	state := make([]byte, blockSize)
+	for i := 0; i < n-1; i++ {
+		xorInto(state, data[i*blockSize:(i+1)*blockSize])
+
+		var err error
+		state, err = c.cipher.EncryptBlock(state)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	xorInto(state, last)
+	return c.cipher.EncryptBlock(state)
+}
+
+func shiftSubkey(block []byte, rb byte) []byte {
+	result := make([]byte, len(block))
+	for i := 0; i < len(block)-1; i++ {
+		result[i] = block[i]<<1 | block[i+1]>>7
+	}
+	result[len(block)-1] = block[len(block)-1] << 1
+
+	if block[0]&0x80 != 0 {
+		result[len(block)-1] ^= rb
+	}
+	return result
+}
+
+func xorInto(dst []byte, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
@@ -0,0 +1,109 @@
+package mac_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/mac"
+	"iSL1/rijndael"
+	"iSL1/tdes"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func keyedCipher(t *testing.T, cipher customlib.BlockCipher, key []byte) customlib.BlockCipher {
+	t.Helper()
+	if err := cipher.SetKey(key); err != nil {
+		t.Fatal(err)
+	}
+	return cipher
+}
+
+func TestCMACAES(t *testing.T) {
+	aes, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmac, err := mac.NewCMAC(keyedCipher(t, aes, mustHex(t, "2b7e151628aed2a6abf7158809cf4f3c")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := mustHex(t, "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+	tests := []struct {
+		length int
+		tag    string
+	}{
+		{0, "bb1d6929e95937287fa37d129b756746"},
+		{16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{40, "dfa66747de9ae63030ca32611497c827"},
+		{64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	}
+
+	for _, tc := range tests {
+		tag, err := cmac.Sum(message[:tc.length])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(tag, mustHex(t, tc.tag)) {
+			t.Errorf("длина %d: тег %x, ожидался %s", tc.length, tag, tc.tag)
+		}
+	}
+}
+
+func TestCMACTDES(t *testing.T) {
+	cipher, err := tdes.NewTDES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmac, err := mac.NewCMAC(keyedCipher(t, cipher, mustHex(t, "8aa83bf8cbda10620bc1bf19fbb6cd58bc313d4a371ca8b5")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmac.Size() != 8 {
+		t.Fatalf("размер тега %d, ожидался 8", cmac.Size())
+	}
+
+	message := mustHex(t, "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	tests := []struct {
+		length int
+		tag    string
+	}{
+		{0, "b7a688e122ffaf95"},
+		{8, "8e8f293136283797"},
+		{20, "743ddbe0ce2dc2ed"},
+		{32, "33e6b1092400eae5"},
+	}
+
+	for _, tc := range tests {
+		tag, err := cmac.Sum(message[:tc.length])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(tag, mustHex(t, tc.tag)) {
+			t.Errorf("длина %d: тег %x, ожидался %s", tc.length, tag, tc.tag)
+		}
+	}
+}
+
+func TestCMACRejectsBlockSize(t *testing.T) {
+	cipher, err := rijndael.NewRijndael(32, rijndael.DefaultModulus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mac.NewCMAC(keyedCipher(t, cipher, make([]byte, 16))); err == nil {
+		t.Error("ожидалась ошибка для блока 32 байта")
+	}
+	if _, err := mac.NewCMAC(nil); err == nil {
+		t.Error("ожидалась ошибка для nil")
+	}
+}
@@ -0,0 +1,56 @@
+package mac
+
+import (
+	"crypto/subtle"
+	"errors"
+	"iSL1/customlib"
+)
+
+type EncryptThenMAC struct {
+	ctx *customlib.CryptoContext
+	mac MAC
+}
+
+func NewEncryptThenMAC(ctx *customlib.CryptoContext, mac MAC) (*EncryptThenMAC, error) {
+	if ctx == nil {
+		return nil, errors.New("контекст шифрования не может быть nil")
+	}
+	if mac == nil {
+		return nil, errors.New("алгоритм MAC не может быть nil")
+	}
+
+	return &EncryptThenMAC{ctx: ctx, mac: mac}, nil
+}
+
+func (etm *EncryptThenMAC) Encrypt(data []byte) ([]byte, error) {
+	ciphertext, err := etm.ctx.Encrypt(data)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := etm.mac.Sum(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ciphertext, tag...), nil
+}
+
+func (etm *EncryptThenMAC) Decrypt(data []byte) ([]byte, error) {
+	tagSize := etm.mac.Size()
+	if len(data) < tagSize {
+		return nil, customlib.ErrAuthentication
+	}
+
+	ciphertext, tag := data[:len(data)-tagSize], data[len(data)-tagSize:]
+
+	expected, err := etm.mac.Sum(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(expected, tag) != 1 {
+		return nil, customlib.ErrAuthentication
+	}
+
+	return etm.ctx.Decrypt(ciphertext)
+}
@@ -0,0 +1,79 @@
+package mac_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"iSL1/customlib"
+	"iSL1/mac"
+	"iSL1/rijndael"
+)
+
+func newEncryptThenMAC(t *testing.T) *mac.EncryptThenMAC {
+	t.Helper()
+	aes, err := rijndael.NewAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := customlib.NewCryptoContextWithCipher(bytes.Repeat([]byte{0x01}, 16), customlib.ModeCBC, customlib.PaddingPKCS7, make([]byte, 16), aes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmac, err := mac.NewHMAC(sha256.New, bytes.Repeat([]byte{0x02}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	etm, err := mac.NewEncryptThenMAC(ctx, hmac)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return etm
+}
+
+func TestEncryptThenMACRoundTrip(t *testing.T) {
+	etm := newEncryptThenMAC(t)
+	plaintext := []byte("encrypt-then-MAC round trip")
+
+	sealed, err := etm.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := etm.Decrypt(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("расшифровано %q, ожидалось %q", opened, plaintext)
+	}
+}
+
+func TestEncryptThenMACTamper(t *testing.T) {
+	etm := newEncryptThenMAC(t)
+	sealed, err := etm.Encrypt([]byte("encrypt-then-MAC tamper test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range sealed {
+		tampered := append([]byte(nil), sealed...)
+		tampered[i] ^= 0x01
+		if _, err := etm.Decrypt(tampered); !errors.Is(err, customlib.ErrAuthentication) {
+			t.Fatalf("байт %d: ожидалась ErrAuthentication, получено %v", i, err)
+		}
+	}
+
+	if _, err := etm.Decrypt(sealed[:10]); !errors.Is(err, customlib.ErrAuthentication) {
+		t.Errorf("короткие данные: ожидалась ErrAuthentication, получено %v", err)
+	}
+	if _, err := etm.Decrypt(sealed[:len(sealed)-1]); !errors.Is(err, customlib.ErrAuthentication) {
+		t.Errorf("усечение: ожидалась ErrAuthentication, получено %v", err)
+	}
+}
+
+func TestEncryptThenMACRequiresArguments(t *testing.T) {
+	if _, err := mac.NewEncryptThenMAC(nil, nil); err == nil {
+		t.Error("ожидалась ошибка для nil")
+	}
+}
@@ -0,0 +1,31 @@
+package mac
+
+import (
+	"crypto/hmac"
+	"errors"
+	"hash"
+)
+
+type HMAC struct {
+	h    func() hash.Hash
+	key  []byte
+	size int
+}
+
+func NewHMAC(h func() hash.Hash, key []byte) (*HMAC, error) {
+	if h == nil {
+		return nil, errors.New("хеш-функция не может быть nil")
+	}
+
+	return &HMAC{h: h, key: append([]byte(nil), key...), size: h().Size()}, nil
+}
+
+func (m *HMAC) Size() int {
+	return m.size
+}
+
+func (m *HMAC) Sum(data []byte) ([]byte, error) {
+	mac := hmac.New(m.h, m.key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
@@ -0,0 +1,66 @@
+package mac_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"testing"
+
+	"iSL1/mac"
+)
+
+func TestHMACVectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      []byte
+		data     string
+		h        func() hash.Hash
+		expected string
+	}{
+		{"case1-sha256", bytes.Repeat([]byte{0x0b}, 20), "Hi There", sha256.New, "b0344c61d8db38535ca8afceaf0bf12b881dc200c9833da726e9376c2e32cff7"},
+		{"case1-sha512", bytes.Repeat([]byte{0x0b}, 20), "Hi There", sha512.New, "87aa7cdea5ef619d4ff0b4241a1d6cb02379f4e2ce4ec2787ad0b30545e17cdedaa833b7d6b8a702038b274eaea3f4e4be9d914eeb61f1702e696c203a126854"},
+		{"case2-sha256", []byte("Jefe"), "what do ya want for nothing?", sha256.New, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+		{"case2-sha512", []byte("Jefe"), "what do ya want for nothing?", sha512.New, "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737"},
+		{"case6-sha256", bytes.Repeat([]byte{0xaa}, 131), "Test Using Larger Than Block-Size Key - Hash Key First", sha256.New, "60e431591ee0b67f0d8a26aacbf5b77f8e0bc6213728c5140546040f0ee37f54"},
+		{"case6-sha512", bytes.Repeat([]byte{0xaa}, 131), "Test Using Larger Than Block-Size Key - Hash Key First", sha512.New, "80b24263c7c1a3ebb71493c1dd7be8b49b46d1f41b4aeec1121b013783f8f3526b56d037e05f2598bd0fd2215d6a1e5295e64f73f63f0aec8b915a985d786598"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hmac, err := mac.NewHMAC(tc.h, tc.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := mustHex(t, tc.expected)
+			if hmac.Size() != len(expected) {
+				t.Errorf("размер %d, ожидался %d", hmac.Size(), len(expected))
+			}
+
+			tag, err := hmac.Sum([]byte(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(tag, expected) {
+				t.Errorf("тег %x, ожидался %x", tag, expected)
+			}
+		})
+	}
+}
+
+func TestHMACCopiesKey(t *testing.T) {
+	key := []byte("Jefe")
+	hmac, err := mac.NewHMAC(sha256.New, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key[0] = 'X'
+
+	tag, err := hmac.Sum([]byte("what do ya want for nothing?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tag, mustHex(t, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843")) {
+		t.Error("изменение ключа вызывающим повлияло на HMAC")
+	}
+}
@@ -0,0 +1,236 @@
+package rijndael
+
+import (
+	"errors"
+	"iSL1/customlib"
+	"iSL1/gf256"
+)
+
+const DefaultModulus uint16 = 0x11B
+
+var (
+	_ customlib.BlockCipher        = (*Rijndael)(nil)
+	_ customlib.BlockCipherFactory = (*Rijndael)(nil)
+)
+
+type Rijndael struct {
+	blockSize int
+	nb        int
+	nr        int
+	modulus   uint16
+	sBox      [256]byte
+	invSBox   [256]byte
+	shifts    [4]int
+	roundKeys []byte
+}
+
+func NewRijndael(blockSize int, modulus uint16) (*Rijndael, error) {
+	if blockSize != 16 && blockSize != 24 && blockSize != 32 {
+		return nil, errors.New("размер блока должен быть 16, 24 или 32 байта")
+	}
+	ok, err := gf256.IsIrreducible(modulus)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, &gf256.ReducibleModulusError{Modulus: modulus}
+	}
+
+	r := &Rijndael{
+		blockSize: blockSize,
+		nb:        blockSize / 4,
+		modulus:   modulus,
+	}
+
+	err = r.generateSBox()
+	if err != nil {
+		return nil, err
+	}
+
+	switch r.nb {
+	case 4, 6:
+		r.shifts = [4]int{0, 1, 2, 3}
+	case 8:
+		r.shifts = [4]int{0, 1, 3, 4}
+	}
+
+	return r, nil
+}
+
+func NewAES() (*Rijndael, error) {
+	return NewRijndael(16, DefaultModulus)
+}
+
+func (r *Rijndael) NewBlockCipher() (customlib.BlockCipher, error) {
+	return NewRijndael(r.blockSize, r.modulus)
+}
+
+func (r *Rijndael) BlockSize() int {
+	return r.blockSize
+}
+
+func (r *Rijndael) AlgorithmID() customlib.AlgorithmID {
+	return customlib.AlgorithmRijndael
+}
+
+func (r *Rijndael) SetKey(key []byte) error {
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return errors.New("ключ должен быть длиной 16, 24 или 32 байта")
+	}
+
+	nk := len(key) / 4
+	nr := max(nk, r.nb) + 6
+	totalWords := r.nb * (nr + 1)
+
+	words := make([]byte, totalWords*4)
+	copy(words, key)
+
+	rcon := byte(1)
+	for i := nk; i < totalWords; i++ {
+		temp := make([]byte, 4)
+		copy(temp, words[(i-1)*4:i*4])
+
+		if i%nk == 0 {
+			temp[0], temp[1], temp[2], temp[3] = temp[1], temp[2], temp[3], temp[0]
+			r.subWord(temp)
+			temp[0] ^= rcon
+			rcon = r.mul(rcon, 2)
+		} else if nk > 6 && i%nk == 4 {
+			r.subWord(temp)
+		}
+
+		for j := 0; j < 4; j++ {
+			words[i*4+j] = words[(i-nk)*4+j] ^ temp[j]
+		}
+	}
+
+	r.nr = nr
+	r.roundKeys = words
+	return nil
+}
+
+func (r *Rijndael) EncryptBlock(block []byte) ([]byte, error) {
+	if len(block) != r.blockSize {
+		return nil, errors.New("неверный размер блока")
+	}
+	if r.roundKeys == nil {
+		return nil, errors.New("ключ не установлен")
+	}
+
+	state := make([]byte, r.blockSize)
+	copy(state, block)
+
+	r.addRoundKey(state, 0)
+	for round := 1; round < r.nr; round++ {
+		r.subBytes(state, &r.sBox)
+		state = r.shiftRows(state, false)
+		r.mixColumns(state, [4]byte{2, 3, 1, 1})
+		r.addRoundKey(state, round)
+	}
+	r.subBytes(state, &r.sBox)
+	state = r.shiftRows(state, false)
+	r.addRoundKey(state, r.nr)
+
+	return state, nil
+}
+
+func (r *Rijndael) DecryptBlock(block []byte) ([]byte, error) {
+	if len(block) != r.blockSize {
+		return nil, errors.New("неверный размер блока")
+	}
+	if r.roundKeys == nil {
+		return nil, errors.New("ключ не установлен")
+	}
+
+	state := make([]byte, r.blockSize)
+	copy(state, block)
+
+	r.addRoundKey(state, r.nr)
+	for round := r.nr - 1; round > 0; round-- {
+		state = r.shiftRows(state, true)
+		r.subBytes(state, &r.invSBox)
+		r.addRoundKey(state, round)
+		r.mixColumns(state, [4]byte{0x0E, 0x0B, 0x0D, 0x09})
+	}
+	state = r.shiftRows(state, true)
+	r.subBytes(state, &r.invSBox)
+	r.addRoundKey(state, 0)
+
+	return state, nil
+}
+
+func (r *Rijndael) addRoundKey(state []byte, round int) {
+	roundKey := r.roundKeys[round*r.blockSize : (round+1)*r.blockSize]
+	for i := range state {
+		state[i] ^= roundKey[i]
+	}
+}
+
+func (r *Rijndael) subBytes(state []byte, box *[256]byte) {
+	for i := range state {
+		state[i] = box[state[i]]
+	}
+}
+
+func (r *Rijndael) subWord(word []byte) {
+	for i := range word {
+		word[i] = r.sBox[word[i]]
+	}
+}
+
+func (r *Rijndael) shiftRows(state []byte, inverse bool) []byte {
+	result := make([]byte, len(state))
+	for row := 0; row < 4; row++ {
+		for col := 0; col < r.nb; col++ {
+			shift := r.shifts[row]
+			if inverse {
+				shift = r.nb - shift
+			}
+			result[row+4*col] = state[row+4*((col+shift)%r.nb)]
+		}
+	}
+	return result
+}
+
+func (r *Rijndael) mixColumns(state []byte, coefficients [4]byte) {
+	column := make([]byte, 4)
+	for col := 0; col < r.nb; col++ {
+		copy(column, state[4*col:4*col+4])
+		for row := 0; row < 4; row++ {
+			var value byte
+			for k := 0; k < 4; k++ {
+				value ^= r.mul(coefficients[(k-row+4)%4], column[k])
+			}
+			state[4*col+row] = value
+		}
+	}
+}
+
+func (r *Rijndael) generateSBox() error {
+	for b := 0; b < 256; b++ {
+		var inverse byte
+		if b != 0 {
+			var err error
+			inverse, err = gf256.Inverse(byte(b), r.modulus)
+			if err != nil {
+				return err
+			}
+		}
+
+		s := inverse
+		for i := 1; i <= 4; i++ {
+			s ^= inverse<<i | inverse>>(8-i)
+		}
+		s ^= 0x63
+
+		r.sBox[b] = s
+		r.invSBox[s] = byte(b)
+	}
+
+	return nil
+}
+
+func (r *Rijndael) mul(a, b byte) byte {
+	product, _ := gf256.Mul(a, b, r.modulus)
+	return product
+}
@@ -0,0 +1,137 @@
+package rijndael_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"iSL1/gf256"
+	"iSL1/rijndael"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func sequence(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+func TestAESVectors(t *testing.T) {
+	plaintext := mustHex(t, "00112233445566778899aabbccddeeff")
+	tests := []struct {
+		name       string
+		keySize    int
+		ciphertext string
+	}{
+		{"C.1", 16, "69c4e0d86a7b0430d8cdb78070b4c55a"},
+		{"C.2", 24, "dda97ca4864cdfe06eaf70a0ec0d7191"},
+		{"C.3", 32, "8ea2b7ca516745bfeafc49904b496089"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cipher, err := rijndael.NewAES()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cipher.SetKey(sequence(tc.keySize)); err != nil {
+				t.Fatal(err)
+			}
+
+			ciphertext, err := cipher.EncryptBlock(plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(ciphertext, mustHex(t, tc.ciphertext)) {
+				t.Errorf("шифртекст %x, ожидался %s", ciphertext, tc.ciphertext)
+			}
+
+			decrypted, err := cipher.DecryptBlock(ciphertext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("расшифровано %x, ожидалось %x", decrypted, plaintext)
+			}
+		})
+	}
+}
+
+func TestRijndaelBlockSizes(t *testing.T) {
+	for _, blockSize := range []int{16, 24, 32} {
+		for _, keySize := range []int{16, 24, 32} {
+			cipher, err := rijndael.NewRijndael(blockSize, rijndael.DefaultModulus)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cipher.SetKey(sequence(keySize)); err != nil {
+				t.Fatal(err)
+			}
+
+			plaintext := bytes.Repeat([]byte{0xA5}, blockSize)
+			ciphertext, err := cipher.EncryptBlock(plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(ciphertext, plaintext) {
+				t.Errorf("блок %d, ключ %d: шифртекст совпадает с открытым текстом", blockSize, keySize)
+			}
+
+			decrypted, err := cipher.DecryptBlock(ciphertext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("блок %d, ключ %d: расшифровано %x", blockSize, keySize, decrypted)
+			}
+		}
+	}
+}
+
+func TestRijndaelCustomModulus(t *testing.T) {
+	key := sequence(16)
+	plaintext := mustHex(t, "00112233445566778899aabbccddeeff")
+
+	custom, err := rijndael.NewRijndael(16, 0x11D)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := custom.SetKey(key); err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := custom.EncryptBlock(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(ciphertext, mustHex(t, "69c4e0d86a7b0430d8cdb78070b4c55a")) {
+		t.Error("шифртекст совпадает с AES при другом модуле")
+	}
+
+	decrypted, err := custom.DecryptBlock(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("расшифровано %x, ожидалось %x", decrypted, plaintext)
+	}
+}
+
+func TestRijndaelReducibleModulus(t *testing.T) {
+	_, err := rijndael.NewRijndael(16, 0x100)
+	var reducible *gf256.ReducibleModulusError
+	if !errors.As(err, &reducible) {
+		t.Fatalf("ожидалась ReducibleModulusError, получено %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package tdes
+
+import (
+	"errors"
+	"iSL1/customlib"
+	"iSL1/des"
+)
+
+var (
+	_ customlib.BlockCipher        = (*TDES)(nil)
+	_ customlib.BlockCipherFactory = (*TDES)(nil)
+)
+
+type TDES struct {
+	ciphers [3]*des.DES
+}
+
+func NewTDES() (*TDES, error) {
+	tdes := &TDES{}
+	for i := range tdes.ciphers {
+		desCipher, err := des.NewDES()
+		if err != nil {
+			return nil, err
+		}
+		tdes.ciphers[i] = desCipher
+	}
+
+	return tdes, nil
+}
+
+func (tdes *TDES) NewBlockCipher() (customlib.BlockCipher, error) {
+	return NewTDES()
+}
+
+func (tdes *TDES) BlockSize() int {
+	return 8
+}
+
+func (tdes *TDES) AlgorithmID() customlib.AlgorithmID {
+	return customlib.AlgorithmTDES
+}
+
+func (tdes *TDES) SetKey(key []byte) error {
+	var k1, k2, k3 []byte
+
+	switch len(key) {
+	case 24:
+		k1, k2, k3 = key[:8], key[8:16], key[16:24]
+	case 16:
+		k1, k2, k3 = key[:8], key[8:16], key[:8]
+	case 8:
+		k1, k2, k3 = key, key, key
+	default:
+		return errors.New("ключ должен быть длиной 8, 16 или 24 байта")
+	}
+
+	for i, k := range [][]byte{k1, k2, k3} {
+		err := tdes.ciphers[i].SetKey(k)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (tdes *TDES) EncryptBlock(block []byte) ([]byte, error) {
+	if len(block) != 8 {
+		return nil, errors.New("блок должен быть длиной 8 байт")
+	}
+
+	block, err := tdes.ciphers[0].EncryptBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err = tdes.ciphers[1].DecryptBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return tdes.ciphers[2].EncryptBlock(block)
+}
+
+func (tdes *TDES) DecryptBlock(block []byte) ([]byte, error) {
+	if len(block) != 8 {
+		return nil, errors.New("блок должен быть длиной 8 байт")
+	}
+
+	block, err := tdes.ciphers[2].DecryptBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err = tdes.ciphers[1].EncryptBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return tdes.ciphers[0].DecryptBlock(block)
+}
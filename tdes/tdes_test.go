@@ -0,0 +1,112 @@
+package tdes_test
+
+import (
+	"bytes"
+	stddes "crypto/des"
+	"encoding/hex"
+	"testing"
+
+	"iSL1/tdes"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func cryptBlocks(t *testing.T, key, data []byte, decrypt bool) []byte {
+	t.Helper()
+	cipher, err := tdes.NewTDES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cipher.SetKey(key); err != nil {
+		t.Fatal(err)
+	}
+
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i += 8 {
+		var block []byte
+		if decrypt {
+			block, err = cipher.DecryptBlock(data[i : i+8])
+		} else {
+			block, err = cipher.EncryptBlock(data[i : i+8])
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, block...)
+	}
+	return out
+}
+
+func TestTDESKeyingOption1(t *testing.T) {
+	key := mustHex(t, "0123456789abcdef23456789abcdef01456789abcdef0123")
+	plaintext := mustHex(t, "54686520717566636b2062726f776e20666f78206a756d70")
+	expected := mustHex(t, "a826fd8ce53b855fcce21c8112256fe668d5c05dd9b6b900")
+
+	ciphertext := cryptBlocks(t, key, plaintext, false)
+	if !bytes.Equal(ciphertext, expected) {
+		t.Errorf("шифртекст %x, ожидался %x", ciphertext, expected)
+	}
+	if decrypted := cryptBlocks(t, key, ciphertext, true); !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("расшифровано %x, ожидалось %x", decrypted, plaintext)
+	}
+}
+
+func TestTDESKeyingOptions(t *testing.T) {
+	plaintext := mustHex(t, "54686520717566636b2062726f776e20666f78206a756d70")
+	k1, k2, k3 := "0123456789abcdef", "23456789abcdef01", "456789abcdef0123"
+
+	tests := []struct {
+		name     string
+		key      string
+		expanded string
+	}{
+		{"option1", k1 + k2 + k3, k1 + k2 + k3},
+		{"option2", k1 + k2, k1 + k2 + k1},
+		{"option3", k1, k1 + k1 + k1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reference, err := stddes.NewTripleDESCipher(mustHex(t, tc.expanded))
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := make([]byte, len(plaintext))
+			for i := 0; i < len(plaintext); i += 8 {
+				reference.Encrypt(expected[i:i+8], plaintext[i:i+8])
+			}
+
+			ciphertext := cryptBlocks(t, mustHex(t, tc.key), plaintext, false)
+			if !bytes.Equal(ciphertext, expected) {
+				t.Errorf("шифртекст %x, ожидался %x", ciphertext, expected)
+			}
+			if decrypted := cryptBlocks(t, mustHex(t, tc.key), ciphertext, true); !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("расшифровано %x, ожидалось %x", decrypted, plaintext)
+			}
+		})
+	}
+}
+
+func TestTDESKeyingOption3MatchesDES(t *testing.T) {
+	ciphertext := cryptBlocks(t, mustHex(t, "133457799bbcdff1"), mustHex(t, "0123456789abcdef"), false)
+	if expected := mustHex(t, "85e813540f0ab405"); !bytes.Equal(ciphertext, expected) {
+		t.Errorf("шифртекст %x, ожидался %x", ciphertext, expected)
+	}
+}
+
+func TestTDESInvalidKey(t *testing.T) {
+	cipher, err := tdes.NewTDES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cipher.SetKey(make([]byte, 12)); err == nil {
+		t.Error("ожидалась ошибка для ключа длиной 12 байт")
+	}
+}